@@ -9,12 +9,13 @@ import (
 )
 
 func main() {
-	var logFilename string
+	var logFilename, configFilename string
 
 	flag.StringVar(&logFilename, "log", "irc-chat.log", "log filename")
+	flag.StringVar(&configFilename, "config", "irc-chat.json", "config filename")
 	flag.Parse()
 
-	if err := app.Run(logFilename); err != nil {
+	if err := app.Run(logFilename, configFilename); err != nil {
 		fmt.Fprintf(os.Stderr, "Execution error: %v\n", err)
 	}
 }
@@ -1,5 +1,12 @@
 package cmds
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/franciscosbf/irc-client/internal/irc"
+)
+
 type Type int
 
 func (t Type) toString() string {
@@ -16,6 +23,64 @@ func (t Type) toString() string {
 		return "part"
 	case Nick:
 		return "nick"
+	case CertFP:
+		return "certfp"
+	case PrivMsg:
+		return "msg"
+	case Query:
+		return "query"
+	case Close:
+		return "close"
+	case Me:
+		return "me"
+	case CTCP:
+		return "ctcp"
+	case DCC:
+		return "dcc"
+	case Names:
+		return "names"
+	case Mode:
+		return "mode"
+	case Topic:
+		return "topic"
+	case Kick:
+		return "kick"
+	case KickBan:
+		return "kickban"
+	case Ban:
+		return "ban"
+	case Unban:
+		return "unban"
+	case Op:
+		return "op"
+	case Deop:
+		return "deop"
+	case Voice:
+		return "voice"
+	case Devoice:
+		return "devoice"
+	case Whois:
+		return "whois"
+	case WhoWas:
+		return "whowas"
+	case Who:
+		return "who"
+	case List:
+		return "list"
+	case Invite:
+		return "invite"
+	case Knock:
+		return "knock"
+	case Reconnect:
+		return "reconnect"
+	case Away:
+		return "away"
+	case Back:
+		return "back"
+	case NickServ:
+		return "ns"
+	case ChanServ:
+		return "cs"
 	case Quit:
 		return "quit"
 	case Msg:
@@ -32,6 +97,35 @@ const (
 	Join
 	Part
 	Nick
+	CertFP
+	PrivMsg
+	Query
+	Close
+	Me
+	CTCP
+	DCC
+	Names
+	Mode
+	Topic
+	Kick
+	KickBan
+	Ban
+	Unban
+	Op
+	Deop
+	Voice
+	Devoice
+	Whois
+	WhoWas
+	Who
+	List
+	Invite
+	Knock
+	Reconnect
+	Away
+	Back
+	NickServ
+	ChanServ
 	Quit
 	Msg
 )
@@ -40,15 +134,39 @@ type Cmd interface {
 	GetType() Type
 }
 
+const maskedSecret = "****"
+
+type maskedCmd interface {
+	maskInput(input string, isService func(string) bool) string
+}
+
+func HistoryInput(input string, cmd Cmd, isService func(string) bool) string {
+	if masked, ok := cmd.(maskedCmd); ok {
+		return masked.maskInput(input, isService)
+	}
+
+	return input
+}
+
 type ConnectCmd struct {
-	Host           string
-	Nickname, Name string
+	Host                      string
+	Nickname, Name            string
+	SASLAccount, SASLPassword string
 }
 
 func (ConnectCmd) GetType() Type {
 	return Connect
 }
 
+func (c ConnectCmd) maskInput(input string, _ func(string) bool) string {
+	if c.SASLPassword == "" {
+		return input
+	}
+
+	return fmt.Sprintf("/%s -sasl %s %s %s %s %s",
+		Connect.toString(), c.SASLAccount, maskedSecret, c.Host, c.Nickname, c.Name)
+}
+
 type DisconnectCmd struct{}
 
 func (DisconnectCmd) GetType() Type {
@@ -57,6 +175,7 @@ func (DisconnectCmd) GetType() Type {
 
 type JoinCmd struct {
 	Tag string
+	Key string
 }
 
 func (JoinCmd) GetType() Type {
@@ -79,6 +198,293 @@ func (NickCmd) GetType() Type {
 	return Nick
 }
 
+type CertFPCmd struct {
+	Host string
+}
+
+func (CertFPCmd) GetType() Type {
+	return CertFP
+}
+
+type PrivMsgCmd struct {
+	Target     string
+	MsgContent string
+}
+
+func (PrivMsgCmd) GetType() Type {
+	return PrivMsg
+}
+
+func (c PrivMsgCmd) maskInput(input string, isService func(string) bool) string {
+	for target := range strings.SplitSeq(c.Target, ",") {
+		if isService(target) {
+			return fmt.Sprintf("/%s %s %s", PrivMsg.toString(), c.Target, irc.MaskServiceCommand(c.MsgContent))
+		}
+	}
+
+	return input
+}
+
+type QueryCmd struct {
+	Nickname string
+}
+
+func (QueryCmd) GetType() Type {
+	return Query
+}
+
+type CloseCmd struct{}
+
+func (CloseCmd) GetType() Type {
+	return Close
+}
+
+type MeCmd struct {
+	Action string
+}
+
+func (MeCmd) GetType() Type {
+	return Me
+}
+
+type CTCPCmd struct {
+	Target  string
+	Command string
+	Params  string
+}
+
+func (CTCPCmd) GetType() Type {
+	return CTCP
+}
+
+type DCCSendCmd struct {
+	Nickname string
+	Path     string
+}
+
+func (DCCSendCmd) GetType() Type {
+	return DCC
+}
+
+type DCCCancelCmd struct {
+	ID int
+}
+
+func (DCCCancelCmd) GetType() Type {
+	return DCC
+}
+
+type DCCChatCmd struct {
+	Nickname string
+}
+
+func (DCCChatCmd) GetType() Type {
+	return DCC
+}
+
+type DCCListCmd struct{}
+
+func (DCCListCmd) GetType() Type {
+	return DCC
+}
+
+type NamesCmd struct {
+	Tag string
+}
+
+func (NamesCmd) GetType() Type {
+	return Names
+}
+
+type ModeCmd struct {
+	Target string
+	Modes  string
+	Args   []string
+}
+
+func (ModeCmd) GetType() Type {
+	return Mode
+}
+
+type TopicCmd struct {
+	Tag    string
+	Topic  string
+	Delete bool
+}
+
+func (TopicCmd) GetType() Type {
+	return Topic
+}
+
+type KickCmd struct {
+	Tag      string
+	Nickname string
+	Reason   string
+}
+
+func (KickCmd) GetType() Type {
+	return Kick
+}
+
+type KickBanCmd struct {
+	Tag      string
+	Nickname string
+	Reason   string
+}
+
+func (KickBanCmd) GetType() Type {
+	return KickBan
+}
+
+type BanCmd struct {
+	Tag    string
+	Target string
+}
+
+func (BanCmd) GetType() Type {
+	return Ban
+}
+
+type UnbanCmd struct {
+	Tag    string
+	Target string
+}
+
+func (UnbanCmd) GetType() Type {
+	return Unban
+}
+
+type OpCmd struct {
+	Tag       string
+	Nicknames []string
+}
+
+func (OpCmd) GetType() Type {
+	return Op
+}
+
+type DeopCmd struct {
+	Tag       string
+	Nicknames []string
+}
+
+func (DeopCmd) GetType() Type {
+	return Deop
+}
+
+type VoiceCmd struct {
+	Tag       string
+	Nicknames []string
+}
+
+func (VoiceCmd) GetType() Type {
+	return Voice
+}
+
+type DevoiceCmd struct {
+	Tag       string
+	Nicknames []string
+}
+
+func (DevoiceCmd) GetType() Type {
+	return Devoice
+}
+
+type WhoisCmd struct {
+	Nickname string
+}
+
+func (WhoisCmd) GetType() Type {
+	return Whois
+}
+
+type WhoWasCmd struct {
+	Nickname string
+}
+
+func (WhoWasCmd) GetType() Type {
+	return WhoWas
+}
+
+type WhoCmd struct {
+	Mask string
+}
+
+func (WhoCmd) GetType() Type {
+	return Who
+}
+
+type ListCmd struct {
+	Conditions string
+	Filter     string
+}
+
+func (ListCmd) GetType() Type {
+	return List
+}
+
+type InviteCmd struct {
+	Nickname string
+	Tag      string
+}
+
+func (InviteCmd) GetType() Type {
+	return Invite
+}
+
+type KnockCmd struct {
+	Tag     string
+	Message string
+}
+
+func (KnockCmd) GetType() Type {
+	return Knock
+}
+
+type ReconnectCmd struct{}
+
+func (ReconnectCmd) GetType() Type {
+	return Reconnect
+}
+
+type AwayCmd struct {
+	Message string
+}
+
+func (AwayCmd) GetType() Type {
+	return Away
+}
+
+type BackCmd struct{}
+
+func (BackCmd) GetType() Type {
+	return Back
+}
+
+type NickServCmd struct {
+	Command string
+}
+
+func (NickServCmd) GetType() Type {
+	return NickServ
+}
+
+func (c NickServCmd) maskInput(string, func(string) bool) string {
+	return "/" + NickServ.toString() + " " + irc.MaskServiceCommand(c.Command)
+}
+
+type ChanServCmd struct {
+	Command string
+}
+
+func (ChanServCmd) GetType() Type {
+	return ChanServ
+}
+
+func (c ChanServCmd) maskInput(string, func(string) bool) string {
+	return "/" + ChanServ.toString() + " " + irc.MaskServiceCommand(c.Command)
+}
+
 type QuitCmd struct{}
 
 func (QuitCmd) GetType() Type {
@@ -103,10 +509,44 @@ func (HelpCmd) HelpMsg() string {
 	return `Available commands:
 /help                             Shows this message
 /connect <host> <nickname> <name> Connects to a network
+  [-sasl <account> <password>]    Authenticates with SASL PLAIN
 /disconnect                       Disconnects from a network
-/join <channel>                   Connects to a channel in the network
+/reconnect                        Reconnects to the current or last network
+/join <channel> [key]             Connects to a channel in the network
 /part <channel>                   Disconnects from a channel in the network
 /nick <nickname>                  Changes your nickname in the network
+/certfp [host]                    Shows the fingerprint of the client certificate
+/msg <targets> <text>             Sends a message to nicknames or channels (comma separated)
+/query <nickname>                 Opens a private chat with a nickname
+/close                            Closes the current private or direct chat
+/me <action>                      Sends an action to the current chat
+/ctcp <target> <command> [args]   Sends a CTCP request
+/dcc send <nickname> <file>       Offers a file to a nickname
+/dcc cancel <id>                  Cancels a file transfer
+/dcc list                         Shows the file transfers
+/dcc chat <nickname>              Offers a direct chat to a nickname
+/names [channel]                  Lists the members of a channel
+/mode <target> [modes] [args]     Shows or changes the modes of a channel or yourself
+/topic [channel] [topic]          Shows or changes the topic of a channel
+/topic -delete [channel]          Clears the topic of a channel
+/kick [channel] <nickname> [why]  Kicks a nickname from a channel
+/kickban [channel] <nick> [why]   Bans and kicks a nickname from a channel
+/ban [channel] <nickname|mask>    Bans a nickname or mask from a channel
+/unban [channel] <nickname|mask>  Removes a ban from a channel
+/op [channel] <nicknames>         Gives operator status to nicknames
+/deop [channel] <nicknames>       Takes operator status from nicknames
+/voice [channel] <nicknames>      Gives voice to nicknames
+/devoice [channel] <nicknames>    Takes voice from nicknames
+/whois <nickname>                 Shows information about a nickname
+/whowas <nickname>                Shows information about a nickname that left
+/who <mask>                       Lists the users matching a channel or mask
+/list [filter]                    Browses the channels of the network (e.g. rust, >100, C<60)
+/invite <nickname> [channel]      Invites a nickname to a channel
+/knock <channel> [message]        Asks for an invite to a channel
+/away [message]                   Marks yourself as away
+/back                             Marks yourself as no longer away
+/ns <command>                     Sends a command to NickServ (e.g. identify, ghost)
+/cs <command>                     Sends a command to ChanServ (e.g. op, access)
 /quit                             Closes the IRC Client
 <bunch of text>                   Sends a message in the current channel`
 }
@@ -3,8 +3,11 @@ package cmds
 import (
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/franciscosbf/irc-client/internal/irc"
 )
 
 type InvalidCmdErr struct {
@@ -27,8 +30,12 @@ func splitNArgs(args string, nArgs int) []string {
 	return strings.SplitN(args, " ", nArgs)
 }
 
-func isNicknameValid(nickname string) bool {
-	if len(nickname) > 9 {
+func isNicknameValid(nickname string, isupport irc.ISupport) bool {
+	if nickname == "" || (isupport.NickLen > 0 && len(nickname) > isupport.NickLen) {
+		return false
+	}
+
+	if isupport.IsChannel(nickname) || nickname[0] == '-' || (nickname[0] >= '0' && nickname[0] <= '9') {
 		return false
 	}
 
@@ -37,9 +44,7 @@ func isNicknameValid(nickname string) bool {
 			(r >= 'A' && r <= 'Z') ||
 			(r >= '0' && r <= '9') ||
 			slices.Contains(specialChs, r) ||
-			(r >= 0x5B || r <= 0x60) ||
-			(r >= 0x7B || r <= 0x7D) ||
-			r == '_') {
+			r == '-') {
 			return false
 		}
 	}
@@ -57,14 +62,43 @@ func isNameValid(name string) bool {
 	return true
 }
 
-func isChannelTagValid(channel string) bool {
-	if !strings.HasPrefix(channel, "#") {
-		return false
+func listConditionOf(condition string) (byte, bool) {
+	var (
+		kind  byte
+		limit string
+	)
+
+	switch {
+	case condition == "":
+		return 0, false
+	case condition[0] == '<' || condition[0] == '>':
+		kind, limit = 'U', condition[1:]
+	case len(condition) > 1 && (condition[1] == '<' || condition[1] == '>'):
+		kind, limit = strings.ToUpper(condition[:1])[0], condition[2:]
+		if kind != 'C' && kind != 'T' {
+			return 0, false
+		}
+	case condition[0] == '!':
+		return 'N', true
+	case strings.ContainsAny(condition, "*?"):
+		return 'M', true
+	default:
+		return 0, false
+	}
+
+	if _, err := strconv.ParseUint(limit, 10, 32); err != nil {
+		return 0, false
 	}
 
-	channel = channel[1:]
+	return kind, true
+}
+
+func isChannelTagValid(channel string, isupport irc.ISupport) bool {
+	if !isupport.IsChannel(channel) {
+		return false
+	}
 
-	if len(channel) > 50 {
+	if isupport.ChannelLen > 0 && len(channel) > isupport.ChannelLen {
 		return false
 	}
 
@@ -77,7 +111,71 @@ func isChannelTagValid(channel string) bool {
 	return true
 }
 
-func Parse(input string) (Cmd, error) {
+func isTargetValid(target string, isupport irc.ISupport) bool {
+	return isNicknameValid(target, isupport) || isChannelTagValid(target, isupport)
+}
+
+func cutChannel(args string, isupport irc.ISupport) (string, string) {
+	if tag, rest := cut(args); isChannelTagValid(tag, isupport) {
+		return tag, rest
+	}
+
+	return "", args
+}
+
+func parseKick(cmdType Type, args string, isupport irc.ISupport) (string, string, string, error) {
+	tag, args := cutChannel(args, isupport)
+	nickname, reason := cut(args)
+	if !isNicknameValid(nickname, isupport) {
+		return "", "", "", InvalidCmdErr{
+			CmdType: cmdType,
+			Reason:  "expecting arguments [channel] <nickname> [reason]",
+		}
+	}
+
+	return tag, nickname, reason, nil
+}
+
+func parseBan(cmdType Type, args string, isupport irc.ISupport) (string, string, error) {
+	tag, target := cutChannel(args, isupport)
+	if target == "" || strings.Contains(target, " ") {
+		return "", "", InvalidCmdErr{
+			CmdType: cmdType,
+			Reason:  "expecting arguments [channel] <nickname|mask>",
+		}
+	}
+	if !strings.ContainsAny(target, "!@") && !isNicknameValid(target, isupport) {
+		return "", "", InvalidCmdErr{
+			CmdType: cmdType,
+			Reason:  "invalid nickname",
+		}
+	}
+
+	return tag, target, nil
+}
+
+func parseNicknames(cmdType Type, args string, isupport irc.ISupport) (string, []string, error) {
+	tag, args := cutChannel(args, isupport)
+	nicknames := strings.Fields(args)
+	if len(nicknames) == 0 {
+		return "", nil, InvalidCmdErr{
+			CmdType: cmdType,
+			Reason:  "expecting arguments [channel] <nicknames>",
+		}
+	}
+	for _, nickname := range nicknames {
+		if !isNicknameValid(nickname, isupport) {
+			return "", nil, InvalidCmdErr{
+				CmdType: cmdType,
+				Reason:  "invalid nickname " + nickname,
+			}
+		}
+	}
+
+	return tag, nicknames, nil
+}
+
+func Parse(input string, isupport irc.ISupport) (Cmd, error) {
 	if !strings.HasPrefix(input, "/") {
 		return MsgCmd{
 			MsgContent: input,
@@ -95,6 +193,29 @@ func Parse(input string) (Cmd, error) {
 		}
 		return HelpCmd{}, nil
 	case Connect.toString():
+		cmd := ConnectCmd{}
+		for strings.HasPrefix(args, "-") {
+			var option string
+			option, args = cut(args)
+			switch option {
+			case "-sasl":
+				optionArgs := splitNArgs(args, 3)
+				if len(optionArgs) < 3 {
+					return nil, InvalidCmdErr{
+						CmdType: Connect,
+						Reason:  "expecting option -sasl <account> <password>",
+					}
+				}
+				cmd.SASLAccount = optionArgs[0]
+				cmd.SASLPassword = optionArgs[1]
+				args = optionArgs[2]
+			default:
+				return nil, InvalidCmdErr{
+					CmdType: Connect,
+					Reason:  "unknown option " + option,
+				}
+			}
+		}
 		args := splitNArgs(args, 3)
 		if len(args) < 3 {
 			return nil, InvalidCmdErr{
@@ -103,7 +224,7 @@ func Parse(input string) (Cmd, error) {
 			}
 		}
 		host := args[0]
-		if !isNicknameValid(args[1]) {
+		if !isNicknameValid(args[1], isupport) {
 			return nil, InvalidCmdErr{
 				CmdType: Connect,
 				Reason:  "invalid nickname",
@@ -117,11 +238,10 @@ func Parse(input string) (Cmd, error) {
 			}
 		}
 		name := args[2]
-		return ConnectCmd{
-			Host:     host,
-			Nickname: nickname,
-			Name:     name,
-		}, nil
+		cmd.Host = host
+		cmd.Nickname = nickname
+		cmd.Name = name
+		return cmd, nil
 	case Disconnect.toString():
 		if args != "" {
 			return nil, InvalidCmdErr{
@@ -137,14 +257,22 @@ func Parse(input string) (Cmd, error) {
 				Reason:  "expecting argument <channel>",
 			}
 		}
-		if !isChannelTagValid(args) {
+		tag, key := cut(args)
+		if !isChannelTagValid(tag, isupport) {
 			return nil, InvalidCmdErr{
 				CmdType: Join,
 				Reason:  "invalid channel",
 			}
 		}
+		if strings.ContainsRune(key, ' ') {
+			return nil, InvalidCmdErr{
+				CmdType: Join,
+				Reason:  "expecting arguments <channel> [key]",
+			}
+		}
 		return JoinCmd{
-			Tag: args,
+			Tag: tag,
+			Key: key,
 		}, nil
 	case Part.toString():
 		if args == "" {
@@ -153,7 +281,7 @@ func Parse(input string) (Cmd, error) {
 				Reason:  "expecting argument <channel>",
 			}
 		}
-		if !isChannelTagValid(args) {
+		if !isChannelTagValid(args, isupport) {
 			return nil, InvalidCmdErr{
 				CmdType: Part,
 				Reason:  "invalid channel",
@@ -169,7 +297,7 @@ func Parse(input string) (Cmd, error) {
 				Reason:  "expecting argument <nickname>",
 			}
 		}
-		if !isNicknameValid(args) {
+		if !isNicknameValid(args, isupport) {
 			return nil, InvalidCmdErr{
 				CmdType: Nick,
 				Reason:  "invalid nickname",
@@ -179,6 +307,418 @@ func Parse(input string) (Cmd, error) {
 		return NickCmd{
 			Nickname: nickname,
 		}, nil
+	case CertFP.toString():
+		if strings.Contains(args, " ") {
+			return nil, InvalidCmdErr{
+				CmdType: CertFP,
+				Reason:  "expecting optional argument [host]",
+			}
+		}
+		return CertFPCmd{
+			Host: args,
+		}, nil
+	case PrivMsg.toString():
+		args := splitNArgs(args, 2)
+		if len(args) < 2 || args[1] == "" {
+			return nil, InvalidCmdErr{
+				CmdType: PrivMsg,
+				Reason:  "expecting arguments <targets> <text>",
+			}
+		}
+		targets := strings.Split(args[0], ",")
+		if limit := isupport.MaxTargetsOf("PRIVMSG"); limit > 0 && len(targets) > limit {
+			return nil, InvalidCmdErr{
+				CmdType: PrivMsg,
+				Reason:  fmt.Sprintf("at most %d targets are allowed", limit),
+			}
+		}
+		for _, target := range targets {
+			if !isTargetValid(target, isupport) {
+				return nil, InvalidCmdErr{
+					CmdType: PrivMsg,
+					Reason:  "invalid target " + target,
+				}
+			}
+		}
+		return PrivMsgCmd{
+			Target:     args[0],
+			MsgContent: args[1],
+		}, nil
+	case Query.toString():
+		if args == "" {
+			return nil, InvalidCmdErr{
+				CmdType: Query,
+				Reason:  "expecting argument <nickname>",
+			}
+		}
+		if !isNicknameValid(args, isupport) {
+			return nil, InvalidCmdErr{
+				CmdType: Query,
+				Reason:  "invalid nickname",
+			}
+		}
+		return QueryCmd{
+			Nickname: args,
+		}, nil
+	case Close.toString():
+		if args != "" {
+			return nil, InvalidCmdErr{
+				CmdType: Close,
+				Reason:  "command doesn't have arguments",
+			}
+		}
+		return CloseCmd{}, nil
+	case Me.toString():
+		if args == "" {
+			return nil, InvalidCmdErr{
+				CmdType: Me,
+				Reason:  "expecting argument <action>",
+			}
+		}
+		return MeCmd{
+			Action: args,
+		}, nil
+	case CTCP.toString():
+		args := splitNArgs(args, 3)
+		if len(args) < 2 || args[1] == "" {
+			return nil, InvalidCmdErr{
+				CmdType: CTCP,
+				Reason:  "expecting arguments <target> <command> [args]",
+			}
+		}
+		if !isTargetValid(args[0], isupport) {
+			return nil, InvalidCmdErr{
+				CmdType: CTCP,
+				Reason:  "invalid target",
+			}
+		}
+		cmd := CTCPCmd{
+			Target:  args[0],
+			Command: strings.ToUpper(args[1]),
+		}
+		if len(args) == 3 {
+			cmd.Params = args[2]
+		}
+		return cmd, nil
+	case DCC.toString():
+		subcmd, args := cut(args)
+		switch subcmd {
+		case "send":
+			args := splitNArgs(args, 2)
+			if len(args) < 2 || args[1] == "" {
+				return nil, InvalidCmdErr{
+					CmdType: DCC,
+					Reason:  "expecting arguments send <nickname> <file>",
+				}
+			}
+			if !isNicknameValid(args[0], isupport) {
+				return nil, InvalidCmdErr{
+					CmdType: DCC,
+					Reason:  "invalid nickname",
+				}
+			}
+			return DCCSendCmd{
+				Nickname: args[0],
+				Path:     args[1],
+			}, nil
+		case "cancel":
+			id, err := strconv.Atoi(args)
+			if err != nil || id <= 0 {
+				return nil, InvalidCmdErr{
+					CmdType: DCC,
+					Reason:  "expecting arguments cancel <id>",
+				}
+			}
+			return DCCCancelCmd{
+				ID: id,
+			}, nil
+		case "list":
+			if args != "" {
+				return nil, InvalidCmdErr{
+					CmdType: DCC,
+					Reason:  "list doesn't have arguments",
+				}
+			}
+			return DCCListCmd{}, nil
+		case "chat":
+			if !isNicknameValid(args, isupport) {
+				return nil, InvalidCmdErr{
+					CmdType: DCC,
+					Reason:  "expecting arguments chat <nickname>",
+				}
+			}
+			return DCCChatCmd{
+				Nickname: args,
+			}, nil
+		}
+		return nil, InvalidCmdErr{
+			CmdType: DCC,
+			Reason:  "expecting one of send, cancel, list or chat",
+		}
+	case Names.toString():
+		if args != "" && !isChannelTagValid(args, isupport) {
+			return nil, InvalidCmdErr{
+				CmdType: Names,
+				Reason:  "expecting optional argument [channel]",
+			}
+		}
+		return NamesCmd{
+			Tag: args,
+		}, nil
+	case Mode.toString():
+		args := strings.Fields(args)
+		if len(args) < 1 {
+			return nil, InvalidCmdErr{
+				CmdType: Mode,
+				Reason:  "expecting arguments <target> [modes] [args]",
+			}
+		}
+		if !isTargetValid(args[0], isupport) {
+			return nil, InvalidCmdErr{
+				CmdType: Mode,
+				Reason:  "invalid target",
+			}
+		}
+		cmd := ModeCmd{
+			Target: args[0],
+		}
+		if len(args) > 1 {
+			if !strings.ContainsAny(args[1][:1], "+-") {
+				return nil, InvalidCmdErr{
+					CmdType: Mode,
+					Reason:  "modes must start with + or -",
+				}
+			}
+			cmd.Modes = args[1]
+			cmd.Args = args[2:]
+		}
+		return cmd, nil
+	case Topic.toString():
+		option, rest := cut(args)
+		if option == "-delete" {
+			tag, topic := cutChannel(rest, isupport)
+			if topic != "" {
+				return nil, InvalidCmdErr{
+					CmdType: Topic,
+					Reason:  "expecting arguments -delete [channel]",
+				}
+			}
+			return TopicCmd{
+				Tag:    tag,
+				Delete: true,
+			}, nil
+		}
+		tag, topic := cutChannel(args, isupport)
+		cmd := TopicCmd{
+			Tag:   tag,
+			Topic: topic,
+		}
+		if isupport.TopicLen > 0 && len(cmd.Topic) > isupport.TopicLen {
+			return nil, InvalidCmdErr{
+				CmdType: Topic,
+				Reason:  fmt.Sprintf("topic can't be longer than %d characters", isupport.TopicLen),
+			}
+		}
+		return cmd, nil
+	case Kick.toString():
+		tag, nickname, reason, err := parseKick(Kick, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return KickCmd{
+			Tag:      tag,
+			Nickname: nickname,
+			Reason:   reason,
+		}, nil
+	case KickBan.toString():
+		tag, nickname, reason, err := parseKick(KickBan, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return KickBanCmd{
+			Tag:      tag,
+			Nickname: nickname,
+			Reason:   reason,
+		}, nil
+	case Ban.toString():
+		tag, target, err := parseBan(Ban, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return BanCmd{
+			Tag:    tag,
+			Target: target,
+		}, nil
+	case Unban.toString():
+		tag, target, err := parseBan(Unban, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return UnbanCmd{
+			Tag:    tag,
+			Target: target,
+		}, nil
+	case Op.toString():
+		tag, nicknames, err := parseNicknames(Op, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return OpCmd{
+			Tag:       tag,
+			Nicknames: nicknames,
+		}, nil
+	case Deop.toString():
+		tag, nicknames, err := parseNicknames(Deop, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return DeopCmd{
+			Tag:       tag,
+			Nicknames: nicknames,
+		}, nil
+	case Voice.toString():
+		tag, nicknames, err := parseNicknames(Voice, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return VoiceCmd{
+			Tag:       tag,
+			Nicknames: nicknames,
+		}, nil
+	case Devoice.toString():
+		tag, nicknames, err := parseNicknames(Devoice, args, isupport)
+		if err != nil {
+			return nil, err
+		}
+		return DevoiceCmd{
+			Tag:       tag,
+			Nicknames: nicknames,
+		}, nil
+	case Whois.toString():
+		if !isNicknameValid(args, isupport) {
+			return nil, InvalidCmdErr{
+				CmdType: Whois,
+				Reason:  "expecting argument <nickname>",
+			}
+		}
+		return WhoisCmd{
+			Nickname: args,
+		}, nil
+	case WhoWas.toString():
+		if !isNicknameValid(args, isupport) {
+			return nil, InvalidCmdErr{
+				CmdType: WhoWas,
+				Reason:  "expecting argument <nickname>",
+			}
+		}
+		return WhoWasCmd{
+			Nickname: args,
+		}, nil
+	case Who.toString():
+		if args == "" || strings.ContainsRune(args, ' ') {
+			return nil, InvalidCmdErr{
+				CmdType: Who,
+				Reason:  "expecting argument <mask>",
+			}
+		}
+		return WhoCmd{
+			Mask: args,
+		}, nil
+	case List.toString():
+		if strings.ContainsRune(args, ' ') {
+			return nil, InvalidCmdErr{
+				CmdType: List,
+				Reason:  "expecting optional argument [filter]",
+			}
+		}
+		conditions := strings.Split(args, ",")
+		if _, ok := listConditionOf(conditions[0]); !ok {
+			return ListCmd{
+				Filter: args,
+			}, nil
+		}
+		for _, condition := range conditions {
+			kind, ok := listConditionOf(condition)
+			if !ok {
+				return nil, InvalidCmdErr{
+					CmdType: List,
+					Reason:  "invalid condition " + condition,
+				}
+			}
+			if !strings.ContainsRune(isupport.EList, rune(kind)) {
+				return nil, InvalidCmdErr{
+					CmdType: List,
+					Reason:  "network doesn't support the condition " + condition,
+				}
+			}
+		}
+		return ListCmd{
+			Conditions: args,
+		}, nil
+	case Invite.toString():
+		nickname, tag := cut(args)
+		if !isNicknameValid(nickname, isupport) || (tag != "" && !isChannelTagValid(tag, isupport)) {
+			return nil, InvalidCmdErr{
+				CmdType: Invite,
+				Reason:  "expecting arguments <nickname> [channel]",
+			}
+		}
+		return InviteCmd{
+			Nickname: nickname,
+			Tag:      tag,
+		}, nil
+	case Knock.toString():
+		tag, message := cut(args)
+		if !isChannelTagValid(tag, isupport) {
+			return nil, InvalidCmdErr{
+				CmdType: Knock,
+				Reason:  "expecting arguments <channel> [message]",
+			}
+		}
+		return KnockCmd{
+			Tag:     tag,
+			Message: message,
+		}, nil
+	case Reconnect.toString():
+		if args != "" {
+			return nil, InvalidCmdErr{
+				CmdType: Reconnect,
+				Reason:  "command doesn't have arguments",
+			}
+		}
+		return ReconnectCmd{}, nil
+	case Away.toString():
+		return AwayCmd{
+			Message: args,
+		}, nil
+	case Back.toString():
+		if args != "" {
+			return nil, InvalidCmdErr{
+				CmdType: Back,
+				Reason:  "command doesn't have arguments",
+			}
+		}
+		return BackCmd{}, nil
+	case NickServ.toString():
+		if args == "" {
+			return nil, InvalidCmdErr{
+				CmdType: NickServ,
+				Reason:  "expecting argument <command>",
+			}
+		}
+		return NickServCmd{
+			Command: args,
+		}, nil
+	case ChanServ.toString():
+		if args == "" {
+			return nil, InvalidCmdErr{
+				CmdType: ChanServ,
+				Reason:  "expecting argument <command>",
+			}
+		}
+		return ChanServCmd{
+			Command: args,
+		}, nil
 	case Quit.toString():
 		if args != "" {
 			return nil, InvalidCmdErr{
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("duration must be a string like \"10m\": %v", err)
+	}
+
+	parsed, err := time.ParseDuration(raw)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+type SASL struct {
+	Mechanism string `json:"mechanism"`
+	Account   string `json:"account"`
+	Password  string `json:"password"`
+}
+
+type ClientCert struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+}
+
+func (c ClientCert) IsSet() bool {
+	return c.CertFile != ""
+}
+
+type CTCP struct {
+	Version string `json:"version"`
+}
+
+type DCC struct {
+	DownloadDir string `json:"downloadDir"`
+	Passive     bool   `json:"passive"`
+	Address     string `json:"address"`
+}
+
+const (
+	BanMaskHost     = "host"
+	BanMaskUserHost = "userhost"
+	BanMaskDomain   = "domain"
+	BanMaskNick     = "nick"
+	BanMaskExact    = "exact"
+)
+
+type Ban struct {
+	MaskStyle string `json:"maskStyle"`
+}
+
+type Invite struct {
+	AutoJoin bool     `json:"autoJoin"`
+	Channels []string `json:"channels"`
+}
+
+type Away struct {
+	Message   string   `json:"message"`
+	AutoAfter Duration `json:"autoAfter"`
+}
+
+type Reconnect struct {
+	Enabled      bool     `json:"enabled"`
+	MaxAttempts  int      `json:"maxAttempts"`
+	InitialDelay Duration `json:"initialDelay"`
+	MaxDelay     Duration `json:"maxDelay"`
+}
+
+type Ping struct {
+	Interval Duration `json:"interval"`
+	Timeout  Duration `json:"timeout"`
+}
+
+type Nick struct {
+	Alternates []string `json:"alternates"`
+	Regain     bool     `json:"regain"`
+}
+
+type Flood struct {
+	Burst    int      `json:"burst"`
+	Interval Duration `json:"interval"`
+}
+
+type Services struct {
+	NickServ string `json:"nickServ"`
+	ChanServ string `json:"chanServ"`
+	Account  string `json:"account"`
+	Password string `json:"password"`
+}
+
+type Network struct {
+	SASL       SASL       `json:"sasl"`
+	ClientCert ClientCert `json:"clientCert"`
+	CTCP       CTCP       `json:"ctcp"`
+	DCC        DCC        `json:"dcc"`
+	Ban        Ban        `json:"ban"`
+	Invite     Invite     `json:"invite"`
+	Away       Away       `json:"away"`
+	Reconnect  Reconnect  `json:"reconnect"`
+	Ping       Ping       `json:"ping"`
+	Nick       Nick       `json:"nick"`
+	Services   Services   `json:"services"`
+	Flood      Flood      `json:"flood"`
+	AutoJoin   []string   `json:"autoJoin"`
+}
+
+func defaultDownloadDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "downloads"
+	}
+
+	return filepath.Join(home, "Downloads")
+}
+
+func defaultNetwork() Network {
+	return Network{
+		CTCP: CTCP{
+			Version: "irc-client (github.com/franciscosbf/irc-client)",
+		},
+		DCC: DCC{
+			DownloadDir: defaultDownloadDir(),
+		},
+		Ban: Ban{
+			MaskStyle: BanMaskHost,
+		},
+		Away: Away{
+			Message: "Away from keyboard",
+		},
+		Reconnect: Reconnect{
+			Enabled:      true,
+			MaxAttempts:  10,
+			InitialDelay: Duration(2 * time.Second),
+			MaxDelay:     Duration(5 * time.Minute),
+		},
+		Services: Services{
+			NickServ: "NickServ",
+			ChanServ: "ChanServ",
+		},
+		Flood: Flood{
+			Burst:    5,
+			Interval: Duration(2 * time.Second),
+		},
+		Ping: Ping{
+			Interval: Duration(time.Minute),
+			Timeout:  Duration(3 * time.Minute),
+		},
+	}
+}
+
+type Config struct {
+	defaults Network
+	networks map[string]Network
+}
+
+func (c Config) GetNetwork(host string) Network {
+	if network, ok := c.networks[host]; ok {
+		return network
+	}
+
+	return c.defaults
+}
+
+type rawConfig struct {
+	Defaults json.RawMessage            `json:"defaults"`
+	Networks map[string]json.RawMessage `json:"networks"`
+}
+
+func Default() Config {
+	return Config{
+		defaults: defaultNetwork(),
+		networks: map[string]Network{},
+	}
+}
+
+func Load(configFilename string) (Config, error) {
+	config := Default()
+
+	content, err := os.ReadFile(configFilename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file %s: %v", configFilename, err)
+	}
+
+	var raw rawConfig
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config file %s: %v", configFilename, err)
+	}
+
+	if raw.Defaults != nil {
+		if err := json.Unmarshal(raw.Defaults, &config.defaults); err != nil {
+			return Config{}, fmt.Errorf("invalid defaults in config file %s: %v", configFilename, err)
+		}
+	}
+
+	for host, rawNetwork := range raw.Networks {
+		network := config.defaults
+		if err := json.Unmarshal(rawNetwork, &network); err != nil {
+			return Config{}, fmt.Errorf("invalid network %s in config file %s: %v", host, configFilename, err)
+		}
+		config.networks[host] = network
+	}
+
+	return config, nil
+}
@@ -0,0 +1,75 @@
+package irc
+
+import "time"
+
+func (n *Network) setAway(away bool) bool {
+	if n.away.Swap(away) == away {
+		return false
+	}
+
+	n.nmx.Lock()
+	nickname := n.nickname
+	message := n.awayMessage
+	n.nmx.Unlock()
+
+	if away {
+		n.handleAway(nickname, message)
+	} else {
+		n.handleAway(nickname, "")
+	}
+
+	return true
+}
+
+func (n *Network) handleAwayReply(msg replyMessage, msgTime time.Time) bool {
+	var away bool
+
+	switch msg.code {
+	case rpl_NOWAWAY:
+		away = true
+	case rpl_UNAWAY:
+		away = false
+	default:
+		return false
+	}
+
+	n.msgs <- NetworkMessage{
+		Time:    msgTime,
+		Content: msg.getParam(0),
+	}
+
+	if n.setAway(away) {
+		n.emitEvent(AwayChangedEvent{
+			Away: away,
+		})
+	}
+
+	return true
+}
+
+func (n *Network) IsAway() bool {
+	return n.away.Load()
+}
+
+func (n *Network) GetAwayMessage() string {
+	n.nmx.Lock()
+	defer n.nmx.Unlock()
+
+	return n.awayMessage
+}
+
+func (n *Network) SetAway(message string) error {
+	n.nmx.Lock()
+	n.awayMessage = message
+	n.nmx.Unlock()
+
+	awayMsg := awayMessage{
+		content: message,
+	}
+	return n.conn.write(awayMsg.encode())
+}
+
+func (n *Network) Back() error {
+	awayMsg := awayMessage{}
+	return n.conn.write(awayMsg.encode())
+}
@@ -0,0 +1,254 @@
+package irc
+
+import (
+	"slices"
+	"strings"
+	"sync"
+)
+
+const capVersion = "302"
+
+var supportedCaps = []string{
+	"account-notify",
+	"away-notify",
+	"cap-notify",
+	"chghost",
+	"extended-join",
+	"invite-notify",
+	"multi-prefix",
+	"server-time",
+	"userhost-in-names",
+}
+
+type capabilities struct {
+	mx          sync.Mutex
+	negotiating bool
+	pendingReqs int
+	available   map[string]string
+	enabled     map[string]struct{}
+}
+
+func (c *capabilities) startNegotiation() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.negotiating = true
+	c.pendingReqs = 0
+	c.available = map[string]string{}
+	c.enabled = map[string]struct{}{}
+}
+
+func (c *capabilities) finishNegotiation() bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	if !c.negotiating {
+		return false
+	}
+	c.negotiating = false
+
+	return true
+}
+
+func (c *capabilities) isNegotiating() bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	return c.negotiating
+}
+
+func (c *capabilities) addAvailable(caps []string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, capability := range caps {
+		name, value, _ := strings.Cut(capability, "=")
+		c.available[name] = value
+	}
+}
+
+func (c *capabilities) removeAvailable(caps []string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, capability := range caps {
+		name, _, _ := strings.Cut(capability, "=")
+		delete(c.available, name)
+		delete(c.enabled, name)
+	}
+}
+
+func (c *capabilities) wanted(names []string) []string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	caps := []string{}
+	for _, name := range names {
+		if _, ok := c.available[name]; !ok {
+			continue
+		}
+		if _, ok := c.enabled[name]; ok {
+			continue
+		}
+		caps = append(caps, name)
+	}
+
+	if len(caps) > 0 {
+		c.pendingReqs++
+	}
+
+	return caps
+}
+
+func (c *capabilities) acknowledge(caps []string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	for _, name := range caps {
+		if disabled, found := strings.CutPrefix(name, "-"); found {
+			delete(c.enabled, disabled)
+		} else {
+			c.enabled[name] = struct{}{}
+		}
+	}
+}
+
+func (c *capabilities) hold() {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.pendingReqs++
+}
+
+func (c *capabilities) answerReceived() bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	c.pendingReqs = max(0, c.pendingReqs-1)
+
+	return c.negotiating && c.pendingReqs == 0
+}
+
+func (c *capabilities) isEnabled(name string) bool {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	_, ok := c.enabled[name]
+	return ok
+}
+
+func (c *capabilities) getValue(name string) (string, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	value, ok := c.available[name]
+	return value, ok
+}
+
+func (c *capabilities) getEnabled() []string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+
+	caps := []string{}
+	for name := range c.enabled {
+		caps = append(caps, name)
+	}
+	slices.Sort(caps)
+
+	return caps
+}
+
+func (n *Network) wantedCaps() []string {
+	caps := slices.Clone(supportedCaps)
+	if n.saslEnabled() {
+		caps = append(caps, "sasl")
+	}
+
+	return caps
+}
+
+func (n *Network) requestCaps() (bool, error) {
+	caps := n.caps.wanted(n.wantedCaps())
+	if len(caps) == 0 {
+		return false, nil
+	}
+
+	capMsg := capMessage{
+		subcommand: "REQ",
+		caps:       caps,
+	}
+	return true, n.conn.write(capMsg.encode())
+}
+
+func (n *Network) endCapNegotiation() error {
+	if !n.caps.finishNegotiation() {
+		return nil
+	}
+
+	capMsg := capMessage{
+		subcommand: "END",
+	}
+	return n.conn.write(capMsg.encode())
+}
+
+func (n *Network) handleCap(msg capMessage) error {
+	switch msg.subcommand {
+	case "LS":
+		n.caps.addAvailable(msg.caps)
+		if msg.multiline || !n.caps.isNegotiating() {
+			break
+		}
+		if err := n.checkSASLSupport(); err != nil {
+			return err
+		}
+		requested, err := n.requestCaps()
+		if err != nil {
+			return err
+		}
+		if !requested {
+			return n.endCapNegotiation()
+		}
+	case "ACK":
+		n.caps.acknowledge(msg.caps)
+		n.msgs <- NetworkMessage{
+			Content: "Capabilities enabled: " + strings.Join(msg.caps, " "),
+		}
+		if slices.Contains(msg.caps, "sasl") && n.caps.isNegotiating() && n.saslEnabled() {
+			if err := n.startSASL(); err != nil {
+				return err
+			}
+		}
+		if n.caps.answerReceived() {
+			return n.endCapNegotiation()
+		}
+	case "NAK":
+		n.msgs <- NetworkMessage{
+			Content: "Capabilities refused: " + strings.Join(msg.caps, " "),
+		}
+		if slices.Contains(msg.caps, "sasl") && n.caps.isNegotiating() && n.saslEnabled() {
+			return n.failRegistration("Server refused SASL authentication")
+		}
+		if n.caps.answerReceived() {
+			return n.endCapNegotiation()
+		}
+	case "NEW":
+		n.caps.addAvailable(msg.caps)
+		_, err := n.requestCaps()
+		return err
+	case "DEL":
+		n.caps.removeAvailable(msg.caps)
+		n.msgs <- NetworkMessage{
+			Content: "Capabilities removed: " + strings.Join(msg.caps, " "),
+		}
+	}
+
+	return nil
+}
+
+func (n *Network) HasCapability(name string) bool {
+	return n.caps.isEnabled(name)
+}
+
+func (n *Network) GetCapabilities() []string {
+	return n.caps.getEnabled()
+}
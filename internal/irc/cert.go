@@ -0,0 +1,38 @@
+package irc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/franciscosbf/irc-client/internal/config"
+)
+
+func LoadClientCertificate(clientCert config.ClientCert) (tls.Certificate, error) {
+	if !clientCert.IsSet() {
+		return tls.Certificate{}, errors.New("no client certificate configured")
+	}
+
+	keyFile := clientCert.KeyFile
+	if keyFile == "" {
+		keyFile = clientCert.CertFile
+	}
+
+	cert, err := tls.LoadX509KeyPair(clientCert.CertFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load client certificate: %v", err)
+	}
+
+	return cert, nil
+}
+
+func CertificateFingerprint(cert tls.Certificate) string {
+	if len(cert.Certificate) == 0 {
+		return ""
+	}
+
+	sum := sha256.Sum256(cert.Certificate[0])
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,139 @@
+package irc
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ctcpDelimiter = "\x01"
+
+var supportedCTCPs = []string{"ACTION", "CLIENTINFO", "DCC", "PING", "TIME", "VERSION"}
+
+type ctcpPayload struct {
+	command, params string
+}
+
+func (p ctcpPayload) encode() string {
+	content := p.command
+	if p.params != "" {
+		content += " " + p.params
+	}
+
+	return ctcpDelimiter + content + ctcpDelimiter
+}
+
+func decodeCTCP(content string) (ctcpPayload, bool) {
+	if !strings.HasPrefix(content, ctcpDelimiter) {
+		return ctcpPayload{}, false
+	}
+
+	content = strings.TrimPrefix(content, ctcpDelimiter)
+	content = strings.TrimSuffix(content, ctcpDelimiter)
+	command, params, _ := strings.Cut(content, " ")
+
+	return ctcpPayload{
+		command: strings.ToUpper(command),
+		params:  params,
+	}, true
+}
+
+func (n *Network) sendCTCPReply(target string, payload ctcpPayload) error {
+	noticeMsg := noticeMessage{
+		target:  target,
+		content: payload.encode(),
+	}
+	return n.conn.write(noticeMsg.encode())
+}
+
+func (n *Network) handleCTCPRequest(sender, target string, payload ctcpPayload, msgTime time.Time) error {
+	if payload.command == "ACTION" {
+		n.deliverPrivMessage(sender, target, ChannelMessage{
+			Time:    msgTime,
+			Kind:    ActionMessage,
+			Sender:  sender,
+			Content: payload.params,
+		})
+		return nil
+	}
+
+	if payload.command == "DCC" {
+		n.handleDCC(sender, payload.params, msgTime)
+		return nil
+	}
+
+	n.msgs <- NetworkMessage{
+		Time:    msgTime,
+		Content: fmt.Sprintf("CTCP %s request from %s", payload.command, sender),
+	}
+
+	reply := ctcpPayload{
+		command: payload.command,
+	}
+	switch payload.command {
+	case "VERSION":
+		reply.params = n.config.CTCP.Version
+	case "PING":
+		reply.params = payload.params
+	case "TIME":
+		reply.params = time.Now().Format(time.RFC1123Z)
+	case "CLIENTINFO":
+		reply.params = strings.Join(supportedCTCPs, " ")
+	default:
+		log.Printf("Unknown CTCP request -> %s\n", payload.encode())
+		return nil
+	}
+
+	return n.sendCTCPReply(sender, reply)
+}
+
+func (n *Network) handleCTCPReply(sender string, payload ctcpPayload, msgTime time.Time) {
+	if payload.command == "DCC" {
+		n.handleDCC(sender, payload.params, msgTime)
+		return
+	}
+
+	content := payload.params
+	if payload.command == "PING" {
+		if sent, err := strconv.ParseInt(payload.params, 10, 64); err == nil {
+			lag := time.Since(time.Unix(0, sent)).Round(time.Millisecond)
+			content = lag.String()
+		}
+	}
+
+	n.msgs <- NetworkMessage{
+		Time:    msgTime,
+		Content: fmt.Sprintf("CTCP %s reply from %s: %s", payload.command, sender, content),
+	}
+}
+
+func (n *Network) SendCTCP(target, command, params string) error {
+	command = strings.ToUpper(command)
+	if command == "PING" && params == "" {
+		params = strconv.FormatInt(time.Now().UnixNano(), 10)
+	}
+
+	payload := ctcpPayload{
+		command: command,
+		params:  params,
+	}
+	privMsg := privMessage{
+		target:  target,
+		content: payload.encode(),
+	}
+	return n.conn.write(privMsg.encode())
+}
+
+func (n *Network) sendAction(target, content string) error {
+	payload := ctcpPayload{
+		command: "ACTION",
+		params:  content,
+	}
+	privMsg := privMessage{
+		target:  target,
+		content: payload.encode(),
+	}
+	return n.conn.write(privMsg.encode())
+}
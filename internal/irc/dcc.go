@@ -0,0 +1,819 @@
+package irc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	dccTimeout     = 2 * time.Minute
+	dccDialTimeout = 10 * time.Second
+	dccBufSize     = 32 * 1024
+
+	dccPartialSuffix = ".part"
+)
+
+var errDCCClosedEarly = errors.New("connection closed before the transfer was completed")
+
+type dccTransport interface {
+	listen() (net.Listener, int, error)
+	dial(ip string, port int) (net.Conn, error)
+}
+
+type tcpTransport struct{}
+
+func (tcpTransport) listen() (net.Listener, int, error) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return listener, listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+func (tcpTransport) dial(ip string, port int) (net.Conn, error) {
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	return net.DialTimeout("tcp", addr, dccDialTimeout)
+}
+
+func acceptDCCConn(listener net.Listener) (net.Conn, error) {
+	if deadliner, ok := listener.(interface{ SetDeadline(time.Time) error }); ok {
+		_ = deadliner.SetDeadline(time.Now().Add(dccTimeout))
+	}
+
+	conn, err := listener.Accept()
+	_ = listener.Close()
+
+	return conn, err
+}
+
+type dccRequest struct {
+	kind     string
+	subkind  string
+	argument string
+	ip       string
+	port     int
+	size     int64
+	token    string
+}
+
+func quoteDCCArgument(argument string) string {
+	if strings.Contains(argument, " ") {
+		return `"` + argument + `"`
+	}
+
+	return argument
+}
+
+func encodeDCCAddress(ip string) string {
+	if ipv4 := net.ParseIP(ip).To4(); ipv4 != nil {
+		return strconv.FormatUint(uint64(binary.BigEndian.Uint32(ipv4)), 10)
+	}
+
+	return ip
+}
+
+func decodeDCCAddress(raw string) (string, error) {
+	if value, err := strconv.ParseUint(raw, 10, 32); err == nil {
+		ip := make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(ip, uint32(value))
+		return ip.String(), nil
+	}
+
+	if ip := net.ParseIP(raw); ip != nil {
+		return ip.String(), nil
+	}
+
+	return "", fmt.Errorf("invalid address %s", raw)
+}
+
+func parseDCCRequest(params string) (dccRequest, error) {
+	var req dccRequest
+
+	kind, rest := cutDCCField(params)
+	req.kind = strings.ToUpper(kind)
+	if req.kind == "REJECT" {
+		var subkind string
+		subkind, rest = cutDCCField(rest)
+		req.subkind = strings.ToUpper(subkind)
+	}
+
+	if strings.HasPrefix(rest, `"`) {
+		argument, after, found := strings.Cut(rest[1:], `"`)
+		if !found {
+			return req, errors.New("unterminated quoted argument")
+		}
+		req.argument = argument
+		rest = strings.TrimLeft(after, " ")
+	} else {
+		req.argument, rest = cutDCCField(rest)
+	}
+
+	fields := strings.Fields(rest)
+
+	var err error
+	switch req.kind {
+	case "SEND", "CHAT":
+		if len(fields) < 2 {
+			return req, errors.New("missing address or port")
+		}
+		if req.ip, err = decodeDCCAddress(fields[0]); err != nil {
+			return req, err
+		}
+		fields = fields[1:]
+	case "RESUME", "ACCEPT":
+	case "REJECT":
+		return req, nil
+	default:
+		return req, fmt.Errorf("unknown DCC type %s", req.kind)
+	}
+
+	if len(fields) < 1 {
+		return req, errors.New("missing port")
+	}
+	if req.port, err = strconv.Atoi(fields[0]); err != nil {
+		return req, fmt.Errorf("invalid port %s", fields[0])
+	}
+	fields = fields[1:]
+
+	if req.kind != "CHAT" {
+		if len(fields) < 1 {
+			return req, errors.New("missing size")
+		}
+		if req.size, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+			return req, fmt.Errorf("invalid size %s", fields[0])
+		}
+		fields = fields[1:]
+	}
+
+	if len(fields) > 0 {
+		req.token = fields[0]
+	}
+
+	return req, nil
+}
+
+func cutDCCField(raw string) (string, string) {
+	before, after, _ := strings.Cut(strings.TrimLeft(raw, " "), " ")
+	return before, strings.TrimLeft(after, " ")
+}
+
+type TransferState int
+
+const (
+	TransferPending TransferState = iota
+	TransferWaiting
+	TransferActive
+	TransferDone
+	TransferFailed
+	TransferRejected
+	TransferCanceled
+)
+
+func (s TransferState) String() string {
+	switch s {
+	case TransferPending:
+		return "pending"
+	case TransferWaiting:
+		return "waiting"
+	case TransferActive:
+		return "active"
+	case TransferDone:
+		return "done"
+	case TransferFailed:
+		return "failed"
+	case TransferRejected:
+		return "rejected"
+	case TransferCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+func (s TransferState) IsFinished() bool {
+	return s >= TransferDone
+}
+
+type DCCTransfer struct {
+	id          int
+	network     *Network
+	upload      bool
+	peer        string
+	filename    string
+	size        int64
+	transferred atomic.Int64
+
+	mx       sync.Mutex
+	state    TransferState
+	err      error
+	path     string
+	offset   int64
+	ip       string
+	port     int
+	token    string
+	listener net.Listener
+	conn     net.Conn
+}
+
+func (t *DCCTransfer) GetID() int {
+	return t.id
+}
+
+func (t *DCCTransfer) IsUpload() bool {
+	return t.upload
+}
+
+func (t *DCCTransfer) GetPeer() string {
+	return t.peer
+}
+
+func (t *DCCTransfer) GetFilename() string {
+	return t.filename
+}
+
+func (t *DCCTransfer) GetSize() int64 {
+	return t.size
+}
+
+func (t *DCCTransfer) GetPosition() int64 {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	return t.offset + t.transferred.Load()
+}
+
+func (t *DCCTransfer) GetPath() string {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	return t.path
+}
+
+func (t *DCCTransfer) GetState() TransferState {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	return t.state
+}
+
+func (t *DCCTransfer) GetError() error {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	return t.err
+}
+
+func (t *DCCTransfer) changeState(from, to TransferState) bool {
+	t.mx.Lock()
+	changed := t.state == from
+	if changed {
+		t.state = to
+	}
+	t.mx.Unlock()
+
+	if changed {
+		t.network.emitEvent(TransferUpdatedEvent{
+			Transfer: t,
+		})
+	}
+
+	return changed
+}
+
+func (t *DCCTransfer) end(state TransferState, err error) {
+	t.mx.Lock()
+	if t.state.IsFinished() {
+		t.mx.Unlock()
+		return
+	}
+	t.state = state
+	t.err = err
+	if t.listener != nil {
+		_ = t.listener.Close()
+	}
+	if t.conn != nil {
+		_ = t.conn.Close()
+	}
+	t.mx.Unlock()
+
+	t.network.emitEvent(TransferUpdatedEvent{
+		Transfer: t,
+	})
+}
+
+func (t *DCCTransfer) finish(err error) {
+	if err != nil {
+		t.end(TransferFailed, err)
+	} else {
+		t.end(TransferDone, nil)
+	}
+}
+
+func (t *DCCTransfer) setConn(conn net.Conn) bool {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	if t.state.IsFinished() {
+		return false
+	}
+	t.conn = conn
+
+	return true
+}
+
+func (t *DCCTransfer) getAddress() (string, int, string) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	return t.ip, t.port, t.token
+}
+
+func (t *DCCTransfer) listen() (int, error) {
+	listener, port, err := t.network.dccTransport.listen()
+	if err != nil {
+		return 0, err
+	}
+
+	t.mx.Lock()
+	t.listener = listener
+	t.mx.Unlock()
+
+	return port, nil
+}
+
+func (t *DCCTransfer) acceptConn() (net.Conn, error) {
+	t.mx.Lock()
+	listener := t.listener
+	t.mx.Unlock()
+
+	return acceptDCCConn(listener)
+}
+
+func (t *DCCTransfer) dialPeer(ip string, port int) (net.Conn, error) {
+	return t.network.dccTransport.dial(ip, port)
+}
+
+func (t *DCCTransfer) sendFile(conn net.Conn) {
+	if !t.setConn(conn) {
+		_ = conn.Close()
+		return
+	}
+
+	file, err := os.Open(t.GetPath())
+	if err != nil {
+		t.finish(err)
+		return
+	}
+	defer file.Close()
+
+	t.mx.Lock()
+	offset := t.offset
+	t.mx.Unlock()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		t.finish(err)
+		return
+	}
+
+	t.changeState(TransferWaiting, TransferActive)
+
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	buf := make([]byte, dccBufSize)
+	for {
+		n, rerr := file.Read(buf)
+		if n > 0 {
+			if _, err := conn.Write(buf[:n]); err != nil {
+				t.finish(err)
+				return
+			}
+			t.transferred.Add(int64(n))
+		}
+		if rerr == io.EOF {
+			break
+		} else if rerr != nil {
+			t.finish(rerr)
+			return
+		}
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(dccTimeout))
+	<-drained
+
+	t.finish(nil)
+}
+
+func (t *DCCTransfer) receiveFile(conn net.Conn) {
+	if !t.setConn(conn) {
+		_ = conn.Close()
+		return
+	}
+
+	t.mx.Lock()
+	path, offset := t.path, t.offset
+	t.mx.Unlock()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if offset > 0 {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	file, err := os.OpenFile(path+dccPartialSuffix, flags, 0o644)
+	if err != nil {
+		t.finish(err)
+		return
+	}
+	defer file.Close()
+
+	t.changeState(TransferWaiting, TransferActive)
+
+	buf := make([]byte, dccBufSize)
+	ack := make([]byte, 4)
+	for position := offset; position < t.size; {
+		_ = conn.SetReadDeadline(time.Now().Add(dccTimeout))
+		n, rerr := conn.Read(buf)
+		if n > 0 {
+			if _, err := file.Write(buf[:n]); err != nil {
+				t.finish(err)
+				return
+			}
+			t.transferred.Add(int64(n))
+			position += int64(n)
+
+			binary.BigEndian.PutUint32(ack, uint32(position))
+			_ = conn.SetWriteDeadline(time.Now().Add(dccTimeout))
+			if _, err := conn.Write(ack); err != nil {
+				t.finish(err)
+				return
+			}
+		}
+		if rerr == io.EOF {
+			if position < t.size {
+				t.finish(errDCCClosedEarly)
+				return
+			}
+			break
+		} else if rerr != nil {
+			t.finish(rerr)
+			return
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		t.finish(err)
+		return
+	}
+	t.finish(os.Rename(path+dccPartialSuffix, path))
+}
+
+func (t *DCCTransfer) startDownload() {
+	ip, port, token := t.getAddress()
+
+	if port != 0 {
+		conn, err := t.dialPeer(ip, port)
+		if err != nil {
+			t.finish(err)
+			return
+		}
+		t.receiveFile(conn)
+		return
+	}
+
+	localPort, err := t.listen()
+	if err != nil {
+		t.finish(err)
+		return
+	}
+
+	params := fmt.Sprintf("SEND %s %s %d %d %s",
+		quoteDCCArgument(t.filename), encodeDCCAddress(t.network.dccAddress()), localPort, t.size, token)
+	if err := t.network.SendCTCP(t.peer, "DCC", params); err != nil {
+		t.finish(err)
+		return
+	}
+
+	conn, err := t.acceptConn()
+	if err != nil {
+		t.finish(err)
+		return
+	}
+	t.receiveFile(conn)
+}
+
+func (t *DCCTransfer) Accept() {
+	if !t.changeState(TransferPending, TransferWaiting) {
+		return
+	}
+
+	go func() {
+		path, offset, err := t.network.prepareDownloadPath(t.filename, t.size)
+		if err != nil {
+			t.finish(err)
+			return
+		}
+
+		t.mx.Lock()
+		t.path = path
+		t.offset = offset
+		port, token := t.port, t.token
+		t.mx.Unlock()
+
+		if offset == 0 {
+			t.startDownload()
+			return
+		}
+
+		params := fmt.Sprintf("RESUME %s %d %d", quoteDCCArgument(t.filename), port, offset)
+		if token != "" {
+			params += " " + token
+		}
+		if err := t.network.SendCTCP(t.peer, "DCC", params); err != nil {
+			t.finish(err)
+		}
+	}()
+}
+
+func (t *DCCTransfer) Reject() {
+	if !t.changeState(TransferPending, TransferRejected) {
+		return
+	}
+
+	_ = t.network.sendCTCPReply(t.peer, ctcpPayload{
+		command: "DCC",
+		params:  "REJECT SEND " + quoteDCCArgument(t.filename),
+	})
+}
+
+func (t *DCCTransfer) Cancel() {
+	t.end(TransferCanceled, nil)
+}
+
+type dccTransfers struct {
+	mx     sync.Mutex
+	nextID int
+	byID   map[int]*DCCTransfer
+}
+
+func (n *Network) addTransfer(transfer *DCCTransfer) {
+	n.transfers.mx.Lock()
+	defer n.transfers.mx.Unlock()
+
+	n.transfers.nextID++
+	transfer.id = n.transfers.nextID
+	n.transfers.byID[transfer.id] = transfer
+}
+
+func (n *Network) findTransfer(match func(*DCCTransfer) bool) (*DCCTransfer, bool) {
+	n.transfers.mx.Lock()
+	defer n.transfers.mx.Unlock()
+
+	for _, transfer := range n.transfers.byID {
+		if match(transfer) {
+			return transfer, true
+		}
+	}
+
+	return nil, false
+}
+
+func (n *Network) GetTransfer(id int) (*DCCTransfer, bool) {
+	n.transfers.mx.Lock()
+	defer n.transfers.mx.Unlock()
+
+	transfer, ok := n.transfers.byID[id]
+	return transfer, ok
+}
+
+func (n *Network) GetTransfers() []*DCCTransfer {
+	n.transfers.mx.Lock()
+	defer n.transfers.mx.Unlock()
+
+	transfers := make([]*DCCTransfer, 0, len(n.transfers.byID))
+	for id := 1; id <= n.transfers.nextID; id++ {
+		if transfer, ok := n.transfers.byID[id]; ok {
+			transfers = append(transfers, transfer)
+		}
+	}
+
+	return transfers
+}
+
+func (n *Network) dccAddress() string {
+	if n.config.DCC.Address != "" {
+		return n.config.DCC.Address
+	}
+
+	if addr, ok := n.conn.localAddr().(*net.TCPAddr); ok {
+		return addr.IP.String()
+	}
+
+	return "127.0.0.1"
+}
+
+func (n *Network) prepareDownloadPath(filename string, size int64) (string, int64, error) {
+	dir := n.config.DCC.DownloadDir
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", 0, err
+	}
+
+	name := filepath.Base(filepath.Clean("/" + filename))
+	if name == "/" || name == "." {
+		name = "download"
+	}
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	path := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			break
+		} else if err != nil {
+			return "", 0, err
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+
+	info, err := os.Stat(path + dccPartialSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return path, 0, nil
+	} else if err != nil {
+		return "", 0, err
+	}
+
+	if info.Size() < size {
+		return path, info.Size(), nil
+	}
+
+	return path, 0, nil
+}
+
+func (n *Network) SendFile(nickname, path string) (*DCCTransfer, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	transfer := &DCCTransfer{
+		network:  n,
+		upload:   true,
+		peer:     nickname,
+		filename: filepath.Base(path),
+		size:     info.Size(),
+		state:    TransferWaiting,
+		path:     path,
+	}
+	n.addTransfer(transfer)
+
+	address := encodeDCCAddress(n.dccAddress())
+	filename := quoteDCCArgument(transfer.filename)
+
+	if n.config.DCC.Passive {
+		transfer.token = strconv.Itoa(transfer.id)
+		params := fmt.Sprintf("SEND %s %s 0 %d %s", filename, address, transfer.size, transfer.token)
+		if err := n.SendCTCP(nickname, "DCC", params); err != nil {
+			transfer.finish(err)
+			return nil, err
+		}
+		return transfer, nil
+	}
+
+	port, err := transfer.listen()
+	if err != nil {
+		transfer.finish(err)
+		return nil, err
+	}
+	transfer.mx.Lock()
+	transfer.port = port
+	transfer.mx.Unlock()
+
+	params := fmt.Sprintf("SEND %s %s %d %d", filename, address, port, transfer.size)
+	if err := n.SendCTCP(nickname, "DCC", params); err != nil {
+		transfer.finish(err)
+		return nil, err
+	}
+
+	go func() {
+		conn, err := transfer.acceptConn()
+		if err != nil {
+			transfer.finish(err)
+			return
+		}
+		transfer.sendFile(conn)
+	}()
+
+	return transfer, nil
+}
+
+func (n *Network) matchesTransfer(transfer *DCCTransfer, sender string, upload bool, port int, token string) bool {
+	if !n.isSameName(transfer.peer, sender) || transfer.upload != upload || transfer.GetState() != TransferWaiting {
+		return false
+	}
+
+	_, transferPort, transferToken := transfer.getAddress()
+	if token != "" {
+		return transferToken == token
+	}
+
+	return transferPort == port
+}
+
+func (n *Network) handleDCC(sender, params string, msgTime time.Time) {
+	req, err := parseDCCRequest(params)
+	if err != nil {
+		n.msgs <- NetworkMessage{
+			Time:    msgTime,
+			Content: fmt.Sprintf("Invalid DCC request from %s: %v", sender, err),
+		}
+		return
+	}
+
+	switch req.kind {
+	case "SEND":
+		if req.token != "" && req.port != 0 {
+			transfer, ok := n.findTransfer(func(t *DCCTransfer) bool {
+				return n.matchesTransfer(t, sender, true, 0, req.token)
+			})
+			if ok {
+				go func() {
+					conn, err := transfer.dialPeer(req.ip, req.port)
+					if err != nil {
+						transfer.finish(err)
+						return
+					}
+					transfer.sendFile(conn)
+				}()
+				return
+			}
+		}
+		transfer := &DCCTransfer{
+			network:  n,
+			peer:     sender,
+			filename: req.argument,
+			size:     req.size,
+			ip:       req.ip,
+			port:     req.port,
+			token:    req.token,
+		}
+		n.addTransfer(transfer)
+		n.emitEvent(DCCOfferEvent{
+			Transfer: transfer,
+		})
+	case "CHAT":
+		n.handleDCCChat(sender, req)
+	case "RESUME":
+		transfer, ok := n.findTransfer(func(t *DCCTransfer) bool {
+			return n.matchesTransfer(t, sender, true, req.port, req.token)
+		})
+		if !ok || req.size >= transfer.size {
+			return
+		}
+		transfer.mx.Lock()
+		transfer.offset = req.size
+		transfer.mx.Unlock()
+		reply := fmt.Sprintf("ACCEPT %s %d %d", quoteDCCArgument(req.argument), req.port, req.size)
+		if req.token != "" {
+			reply += " " + req.token
+		}
+		_ = n.SendCTCP(sender, "DCC", reply)
+	case "ACCEPT":
+		transfer, ok := n.findTransfer(func(t *DCCTransfer) bool {
+			return n.matchesTransfer(t, sender, false, req.port, req.token)
+		})
+		if !ok {
+			return
+		}
+		go transfer.startDownload()
+	case "REJECT":
+		if req.subkind == "CHAT" {
+			n.handleDCCChatReject(sender)
+			return
+		}
+		transfer, ok := n.findTransfer(func(t *DCCTransfer) bool {
+			return n.isSameName(t.peer, sender) && t.upload && t.filename == req.argument &&
+				t.GetState() == TransferWaiting
+		})
+		if !ok {
+			return
+		}
+		transfer.end(TransferRejected, nil)
+	}
+}
@@ -0,0 +1,291 @@
+package irc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/franciscosbf/irc-client/internal/config"
+)
+
+const dccTestTimeout = 5 * time.Second
+
+type relayConn struct {
+	nickname string
+	peer     *relayConn
+	incoming chan []byte
+	closed   chan struct{}
+	once     sync.Once
+}
+
+func newRelayPair(first, second string) (*relayConn, *relayConn) {
+	a := &relayConn{
+		nickname: first,
+		incoming: make(chan []byte, messagesBufSize),
+		closed:   make(chan struct{}),
+	}
+	b := &relayConn{
+		nickname: second,
+		incoming: make(chan []byte, messagesBufSize),
+		closed:   make(chan struct{}),
+	}
+	a.peer, b.peer = b, a
+
+	return a, b
+}
+
+func (c *relayConn) getHost() string {
+	return "irc.test"
+}
+
+func (c *relayConn) localAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+func (c *relayConn) read() ([]byte, bool, error) {
+	select {
+	case line := <-c.incoming:
+		return line, false, nil
+	case <-c.closed:
+		return nil, false, io.EOF
+	}
+}
+
+func (c *relayConn) setReadDeadline(time.Time) error {
+	return nil
+}
+
+func (c *relayConn) write(b []byte) error {
+	msg, err := ParseMessage(string(b))
+	if err != nil {
+		return err
+	}
+	if msg.Command != "PRIVMSG" && msg.Command != "NOTICE" {
+		return nil
+	}
+
+	line := ":" + c.nickname + "!user@localhost " + strings.TrimRight(string(b), "\r\n")
+	select {
+	case c.peer.incoming <- []byte(line):
+	case <-c.peer.closed:
+	}
+
+	return nil
+}
+
+func (c *relayConn) close() {
+	c.once.Do(func() {
+		close(c.closed)
+	})
+}
+
+type dccTestPeer struct {
+	network *Network
+	events  chan Event
+	dir     string
+}
+
+func newDCCTestPeer(t *testing.T, conn Connection, passive bool, transport dccTransport) *dccTestPeer {
+	dir := t.TempDir()
+	network := NewNetwork(conn, config.Network{
+		DCC: config.DCC{
+			DownloadDir: dir,
+			Passive:     passive,
+			Address:     "127.0.0.1",
+		},
+	})
+	network.dccTransport = transport
+	network.StartListener()
+
+	peer := &dccTestPeer{
+		network: network,
+		events:  make(chan Event, messagesBufSize),
+		dir:     dir,
+	}
+	go func() {
+		for {
+			event, ok := network.ReceiveEvent()
+			if !ok {
+				close(peer.events)
+				return
+			}
+			peer.events <- event
+		}
+	}()
+	go func() {
+		for {
+			if _, ok := network.ReceiveMessage(); !ok {
+				return
+			}
+		}
+	}()
+	t.Cleanup(network.closeAndCleanup)
+
+	return peer
+}
+
+func newDCCTestPeers(t *testing.T, passive bool, transport dccTransport) (*dccTestPeer, *dccTestPeer) {
+	aliceConn, bobConn := newRelayPair("alice", "bob")
+
+	return newDCCTestPeer(t, aliceConn, passive, transport), newDCCTestPeer(t, bobConn, false, transport)
+}
+
+func waitForEvent[T Event](t *testing.T, peer *dccTestPeer, match func(T) bool) T {
+	t.Helper()
+
+	timeout := time.After(dccTestTimeout)
+	for {
+		select {
+		case event, ok := <-peer.events:
+			if !ok {
+				t.Fatal("network closed while waiting for an event")
+			}
+			if matched, ok := event.(T); ok && match(matched) {
+				return matched
+			}
+		case <-timeout:
+			var zero T
+			t.Fatalf("timed out waiting for %T", zero)
+		}
+	}
+}
+
+func waitForTransfer(t *testing.T, peer *dccTestPeer, transfer *DCCTransfer) {
+	t.Helper()
+
+	event := waitForEvent(t, peer, func(event TransferUpdatedEvent) bool {
+		return event.Transfer == transfer && event.Transfer.GetState().IsFinished()
+	})
+	if state := event.Transfer.GetState(); state != TransferDone {
+		t.Fatalf("transfer ended as %s: %v", state, event.Transfer.GetError())
+	}
+}
+
+func writeTestFile(t *testing.T, size int) (string, []byte) {
+	t.Helper()
+
+	content := make([]byte, size)
+	_, _ = rand.Read(content)
+
+	path := filepath.Join(t.TempDir(), "snippet.log")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, content
+}
+
+func assertDownloaded(t *testing.T, transfer *DCCTransfer, content []byte) {
+	t.Helper()
+
+	received, err := os.ReadFile(transfer.GetPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(received, content) {
+		t.Fatalf("received %d bytes that don't match the %d sent", len(received), len(content))
+	}
+	if position := transfer.GetPosition(); position != int64(len(content)) {
+		t.Fatalf("expected position %d, got %d", len(content), position)
+	}
+}
+
+func testDCCSend(t *testing.T, transport dccTransport, passive bool, resumeFrom int) {
+	alice, bob := newDCCTestPeers(t, passive, transport)
+	path, content := writeTestFile(t, 3*dccBufSize+123)
+
+	if resumeFrom > 0 {
+		partial := filepath.Join(bob.dir, filepath.Base(path)+dccPartialSuffix)
+		if err := os.WriteFile(partial, content[:resumeFrom], 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	upload, err := alice.network.SendFile("bob", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offer := waitForEvent(t, bob, func(DCCOfferEvent) bool { return true })
+	download := offer.Transfer
+	if download.GetFilename() != filepath.Base(path) || download.GetSize() != int64(len(content)) {
+		t.Fatalf("unexpected offer of %s (%d bytes)", download.GetFilename(), download.GetSize())
+	}
+	download.Accept()
+
+	waitForTransfer(t, bob, download)
+	waitForTransfer(t, alice, upload)
+
+	assertDownloaded(t, download, content)
+	if transferred := download.transferred.Load(); transferred != int64(len(content)-resumeFrom) {
+		t.Fatalf("expected %d bytes to be transferred, got %d", len(content)-resumeFrom, transferred)
+	}
+}
+
+func TestDCCSend(t *testing.T) {
+	testDCCSend(t, tcpTransport{}, false, 0)
+}
+
+func TestDCCSendPassive(t *testing.T) {
+	testDCCSend(t, tcpTransport{}, true, 0)
+}
+
+func TestDCCSendResume(t *testing.T) {
+	testDCCSend(t, tcpTransport{}, false, dccBufSize+7)
+}
+
+func TestDCCSendUnbuffered(t *testing.T) {
+	testDCCSend(t, newPipeTransport(), false, 0)
+}
+
+func TestDCCSendKeepsExistingFile(t *testing.T) {
+	alice, bob := newDCCTestPeers(t, false, tcpTransport{})
+	path, content := writeTestFile(t, dccBufSize)
+
+	existing := filepath.Join(bob.dir, filepath.Base(path))
+	if err := os.WriteFile(existing, []byte("unrelated"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := alice.network.SendFile("bob", path); err != nil {
+		t.Fatal(err)
+	}
+
+	offer := waitForEvent(t, bob, func(DCCOfferEvent) bool { return true })
+	download := offer.Transfer
+	download.Accept()
+
+	waitForTransfer(t, bob, download)
+
+	assertDownloaded(t, download, content)
+	if download.GetPath() == existing {
+		t.Fatal("download overwrote an existing file")
+	}
+	if kept, err := os.ReadFile(existing); err != nil || string(kept) != "unrelated" {
+		t.Fatalf("existing file was modified: %q (%v)", kept, err)
+	}
+}
+
+func TestDCCSendReject(t *testing.T) {
+	alice, bob := newDCCTestPeers(t, false, tcpTransport{})
+	path, _ := writeTestFile(t, 10)
+
+	upload, err := alice.network.SendFile("bob", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offer := waitForEvent(t, bob, func(DCCOfferEvent) bool { return true })
+	offer.Transfer.Reject()
+
+	waitForEvent(t, alice, func(event TransferUpdatedEvent) bool {
+		return event.Transfer == upload && event.Transfer.GetState() == TransferRejected
+	})
+}
@@ -0,0 +1,443 @@
+package irc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const dccChatPrefix = "="
+
+var errDCCChatNotConnected = errors.New("chat isn't connected")
+
+type DCCChat struct {
+	peer       string
+	network    *Network
+	closed     atomic.Bool
+	pending    atomic.Bool
+	noMoreMsgs chan struct{}
+	done       chan struct{}
+	msgs       chan ChannelMessage
+
+	mx       sync.Mutex
+	ip       string
+	port     int
+	token    string
+	waiting  bool
+	listener net.Listener
+	conn     Connection
+}
+
+func (dc *DCCChat) signalNoMoreMsgs() {
+	select {
+	case dc.noMoreMsgs <- struct{}{}:
+	default:
+	}
+}
+
+func (dc *DCCChat) GetTag() string {
+	return dccChatPrefix + dc.peer
+}
+
+func (dc *DCCChat) GetPeer() string {
+	return dc.peer
+}
+
+func (dc *DCCChat) IsClosed() bool {
+	return dc.closed.Load()
+}
+
+func (dc *DCCChat) deliver(msg ChannelMessage) {
+	if dc.closed.Load() {
+		return
+	}
+
+	select {
+	case dc.msgs <- msg:
+	case <-dc.done:
+	}
+}
+
+func (dc *DCCChat) notify(content string) {
+	dc.deliver(ChannelMessage{
+		Time:    time.Now(),
+		Content: content,
+	})
+}
+
+func (dc *DCCChat) getConn() Connection {
+	dc.mx.Lock()
+	defer dc.mx.Unlock()
+
+	return dc.conn
+}
+
+func (dc *DCCChat) isWaiting(token string) bool {
+	dc.mx.Lock()
+	defer dc.mx.Unlock()
+
+	return dc.waiting && dc.token == token
+}
+
+func (dc *DCCChat) setConn(conn Connection) bool {
+	dc.mx.Lock()
+	defer dc.mx.Unlock()
+
+	if dc.closed.Load() {
+		return false
+	}
+	dc.waiting = false
+	dc.conn = conn
+
+	return true
+}
+
+func (dc *DCCChat) disconnect() {
+	dc.mx.Lock()
+	defer dc.mx.Unlock()
+
+	dc.waiting = false
+	if dc.listener != nil {
+		_ = dc.listener.Close()
+		dc.listener = nil
+	}
+	if dc.conn != nil {
+		dc.conn.close()
+		dc.conn = nil
+	}
+}
+
+func (dc *DCCChat) listen() (int, error) {
+	listener, port, err := dc.network.dccTransport.listen()
+	if err != nil {
+		return 0, err
+	}
+
+	dc.mx.Lock()
+	dc.listener = listener
+	dc.mx.Unlock()
+
+	return port, nil
+}
+
+func (dc *DCCChat) acceptConn() (net.Conn, error) {
+	dc.mx.Lock()
+	listener := dc.listener
+	dc.mx.Unlock()
+
+	if listener == nil {
+		return nil, errDCCChatNotConnected
+	}
+
+	return acceptDCCConn(listener)
+}
+
+func (dc *DCCChat) dialPeer(ip string, port int) (net.Conn, error) {
+	return dc.network.dccTransport.dial(ip, port)
+}
+
+func (dc *DCCChat) fail(err error) {
+	dc.disconnect()
+	dc.notify(fmt.Sprintf("Failed to connect to %s: %v", dc.peer, err))
+}
+
+func (dc *DCCChat) run(conn Connection) {
+	if !dc.setConn(conn) {
+		conn.close()
+		return
+	}
+
+	dc.notify("Connected to " + dc.peer)
+
+	for {
+		raw, _, err := conn.read()
+		if err != nil {
+			break
+		}
+
+		msg := ChannelMessage{
+			Time:    time.Now(),
+			Sender:  dc.peer,
+			Content: string(raw),
+		}
+		if payload, ok := decodeCTCP(msg.Content); ok {
+			if payload.command != "ACTION" {
+				continue
+			}
+			msg.Kind = ActionMessage
+			msg.Content = payload.params
+		}
+		dc.deliver(msg)
+	}
+
+	if dc.getConn() == conn {
+		dc.disconnect()
+		dc.notify("Chat with " + dc.peer + " was closed")
+	}
+}
+
+func (dc *DCCChat) write(content string) error {
+	conn := dc.getConn()
+	if conn == nil {
+		return errDCCChatNotConnected
+	}
+
+	return conn.write([]byte(content + "\n"))
+}
+
+func (dc *DCCChat) SendMessage(content string) error {
+	return dc.write(content)
+}
+
+func (dc *DCCChat) SendAction(content string) error {
+	return dc.write(ctcpPayload{command: "ACTION", params: content}.encode())
+}
+
+func (dc *DCCChat) ReceiveMessage() (ChannelMessage, bool) {
+	if dc.closed.Load() {
+		return ChannelMessage{}, false
+	}
+
+	select {
+	case <-dc.noMoreMsgs:
+		return ChannelMessage{}, false
+	case msg := <-dc.msgs:
+		return msg, true
+	}
+}
+
+func (dc *DCCChat) connectToPeer() {
+	dc.mx.Lock()
+	ip, port, token := dc.ip, dc.port, dc.token
+	dc.mx.Unlock()
+
+	if port != 0 {
+		conn, err := dc.dialPeer(ip, port)
+		if err != nil {
+			dc.fail(err)
+			return
+		}
+		dc.run(newNetworkConnection(dc.peer, conn, false))
+		return
+	}
+
+	localPort, err := dc.listen()
+	if err != nil {
+		dc.fail(err)
+		return
+	}
+
+	params := fmt.Sprintf("CHAT chat %s %d %s",
+		encodeDCCAddress(dc.network.dccAddress()), localPort, token)
+	if err := dc.network.SendCTCP(dc.peer, "DCC", params); err != nil {
+		dc.fail(err)
+		return
+	}
+
+	conn, err := dc.acceptConn()
+	if err != nil {
+		dc.fail(err)
+		return
+	}
+	dc.run(newNetworkConnection(dc.peer, conn, false))
+}
+
+func (dc *DCCChat) waitForPeer() {
+	conn, err := dc.acceptConn()
+	if err != nil {
+		if !dc.closed.Load() {
+			dc.fail(err)
+		}
+		return
+	}
+	dc.run(newNetworkConnection(dc.peer, conn, false))
+}
+
+func (dc *DCCChat) Accept() {
+	if !dc.pending.CompareAndSwap(true, false) {
+		return
+	}
+
+	dc.notify("Connecting to " + dc.peer + "...")
+
+	go dc.connectToPeer()
+}
+
+func (dc *DCCChat) Reject() {
+	if !dc.pending.CompareAndSwap(true, false) {
+		return
+	}
+
+	_ = dc.network.sendCTCPReply(dc.peer, ctcpPayload{
+		command: "DCC",
+		params:  "REJECT CHAT chat",
+	})
+
+	dc.Close()
+}
+
+func (dc *DCCChat) Close() {
+	if !dc.closed.CompareAndSwap(false, true) {
+		return
+	}
+
+	dc.disconnect()
+
+	close(dc.done)
+	dc.signalNoMoreMsgs()
+
+	dc.network.removeDCCChat(dc)
+}
+
+func newDCCChat(network *Network, peer string) *DCCChat {
+	return &DCCChat{
+		peer:       peer,
+		network:    network,
+		noMoreMsgs: make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		msgs:       make(chan ChannelMessage, messagesBufSize),
+	}
+}
+
+type dccChats struct {
+	mx        sync.Mutex
+	nextToken int
+	chats     []*DCCChat
+}
+
+func (n *Network) addDCCChat(chat *DCCChat) {
+	n.dccChats.mx.Lock()
+	defer n.dccChats.mx.Unlock()
+
+	n.dccChats.chats = append(n.dccChats.chats, chat)
+}
+
+func (n *Network) removeDCCChat(chat *DCCChat) {
+	n.dccChats.mx.Lock()
+	defer n.dccChats.mx.Unlock()
+
+	for i, c := range n.dccChats.chats {
+		if c == chat {
+			n.dccChats.chats = append(n.dccChats.chats[:i], n.dccChats.chats[i+1:]...)
+			return
+		}
+	}
+}
+
+func (n *Network) findDCCChat(match func(*DCCChat) bool) (*DCCChat, bool) {
+	n.dccChats.mx.Lock()
+	defer n.dccChats.mx.Unlock()
+
+	for _, chat := range n.dccChats.chats {
+		if match(chat) {
+			return chat, true
+		}
+	}
+
+	return nil, false
+}
+
+func (n *Network) getDCCChats() []*DCCChat {
+	n.dccChats.mx.Lock()
+	defer n.dccChats.mx.Unlock()
+
+	chats := make([]*DCCChat, len(n.dccChats.chats))
+	copy(chats, n.dccChats.chats)
+
+	return chats
+}
+
+func (n *Network) OfferChat(nickname string) (*DCCChat, error) {
+	if _, ok := n.findDCCChat(func(c *DCCChat) bool { return n.isSameName(c.peer, nickname) }); ok {
+		return nil, fmt.Errorf("already chatting with %s", nickname)
+	}
+
+	n.dccChats.mx.Lock()
+	n.dccChats.nextToken++
+	token := strconv.Itoa(n.dccChats.nextToken)
+	n.dccChats.mx.Unlock()
+
+	chat := newDCCChat(n, nickname)
+	chat.waiting = true
+
+	address := encodeDCCAddress(n.dccAddress())
+
+	if n.config.DCC.Passive {
+		chat.token = token
+		if err := n.SendCTCP(nickname, "DCC", fmt.Sprintf("CHAT chat %s 0 %s", address, token)); err != nil {
+			return nil, err
+		}
+		n.addDCCChat(chat)
+		chat.notify("Waiting for " + nickname + " to accept the chat...")
+		return chat, nil
+	}
+
+	port, err := chat.listen()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := n.SendCTCP(nickname, "DCC", fmt.Sprintf("CHAT chat %s %d", address, port)); err != nil {
+		chat.disconnect()
+		return nil, err
+	}
+	n.addDCCChat(chat)
+	chat.notify("Waiting for " + nickname + " to accept the chat...")
+
+	go chat.waitForPeer()
+
+	return chat, nil
+}
+
+func (n *Network) handleDCCChat(sender string, req dccRequest) {
+	if req.token != "" && req.port != 0 {
+		chat, ok := n.findDCCChat(func(c *DCCChat) bool {
+			return n.isSameName(c.peer, sender) && c.isWaiting(req.token)
+		})
+		if ok {
+			go func() {
+				conn, err := chat.dialPeer(req.ip, req.port)
+				if err != nil {
+					chat.fail(err)
+					return
+				}
+				chat.run(newNetworkConnection(sender, conn, false))
+			}()
+			return
+		}
+	}
+
+	if _, ok := n.findDCCChat(func(c *DCCChat) bool { return n.isSameName(c.peer, sender) }); ok {
+		return
+	}
+
+	chat := newDCCChat(n, sender)
+	chat.ip = req.ip
+	chat.port = req.port
+	chat.token = req.token
+	chat.pending.Store(true)
+	n.addDCCChat(chat)
+
+	n.emitEvent(DCCChatOfferEvent{
+		Chat: chat,
+	})
+}
+
+func (n *Network) handleDCCChatReject(sender string) {
+	chat, ok := n.findDCCChat(func(c *DCCChat) bool {
+		c.mx.Lock()
+		defer c.mx.Unlock()
+
+		return n.isSameName(c.peer, sender) && c.waiting
+	})
+	if !ok {
+		return
+	}
+
+	chat.disconnect()
+	chat.notify(sender + " rejected the chat")
+}
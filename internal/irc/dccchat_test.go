@@ -0,0 +1,169 @@
+package irc
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type pipeTransport struct {
+	mx        sync.Mutex
+	nextPort  int
+	listeners map[int]*pipeListener
+}
+
+type pipeListener struct {
+	transport *pipeTransport
+	port      int
+	conns     chan net.Conn
+	done      chan struct{}
+	once      sync.Once
+}
+
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+
+		l.transport.mx.Lock()
+		delete(l.transport.listeners, l.port)
+		l.transport.mx.Unlock()
+	})
+
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: l.port}
+}
+
+func newPipeTransport() *pipeTransport {
+	return &pipeTransport{
+		listeners: map[int]*pipeListener{},
+	}
+}
+
+func (p *pipeTransport) listen() (net.Listener, int, error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	p.nextPort++
+	listener := &pipeListener{
+		transport: p,
+		port:      p.nextPort,
+		conns:     make(chan net.Conn),
+		done:      make(chan struct{}),
+	}
+	p.listeners[listener.port] = listener
+
+	return listener, listener.port, nil
+}
+
+func (p *pipeTransport) dial(ip string, port int) (net.Conn, error) {
+	p.mx.Lock()
+	listener, ok := p.listeners[port]
+	p.mx.Unlock()
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+
+	client, server := net.Pipe()
+	select {
+	case listener.conns <- server:
+		return client, nil
+	case <-listener.done:
+		return nil, errors.New("connection refused")
+	}
+}
+
+func receiveChatMessage(t *testing.T, chat *DCCChat, sender string) ChannelMessage {
+	t.Helper()
+
+	msgs := make(chan ChannelMessage)
+	go func() {
+		defer close(msgs)
+		for {
+			msg, ok := chat.ReceiveMessage()
+			if !ok {
+				return
+			}
+			if msg.Sender == sender {
+				msgs <- msg
+				return
+			}
+		}
+	}()
+
+	select {
+	case msg, ok := <-msgs:
+		if !ok {
+			t.Fatal("chat closed while waiting for a message")
+		}
+		return msg
+	case <-time.After(dccTestTimeout):
+		t.Fatalf("timed out waiting for a message from %s", sender)
+		return ChannelMessage{}
+	}
+}
+
+func testDCCChat(t *testing.T, passive bool) {
+	alice, bob := newDCCTestPeers(t, passive, newPipeTransport())
+
+	aliceChat, err := alice.network.OfferChat("bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offer := waitForEvent(t, bob, func(DCCChatOfferEvent) bool { return true })
+	bobChat := offer.Chat
+	if bobChat.GetTag() != dccChatPrefix+"alice" {
+		t.Fatalf("unexpected chat tag %s", bobChat.GetTag())
+	}
+	bobChat.Accept()
+
+	deadline := time.Now().Add(dccTestTimeout)
+	for aliceChat.getConn() == nil || bobChat.getConn() == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the chat to connect")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := bobChat.SendMessage("hello alice"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receiveChatMessage(t, aliceChat, "bob"); msg.Content != "hello alice" || msg.Kind != TextMessage {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+
+	if err := aliceChat.SendAction("waves"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := receiveChatMessage(t, bobChat, "alice"); msg.Content != "waves" || msg.Kind != ActionMessage {
+		t.Fatalf("unexpected action %+v", msg)
+	}
+
+	aliceChat.Close()
+	if msg := receiveChatMessage(t, bobChat, ""); !strings.Contains(msg.Content, "closed") {
+		t.Fatalf("expected the chat to be closed, got %+v", msg)
+	}
+}
+
+func TestDCCChat(t *testing.T) {
+	testDCCChat(t, false)
+}
+
+func TestDCCChatPassive(t *testing.T) {
+	testDCCChat(t, true)
+}
@@ -0,0 +1,107 @@
+package irc
+
+import "time"
+
+type Event interface {
+	isEvent()
+}
+
+type RegisteredEvent struct {
+	Nickname string
+}
+
+func (RegisteredEvent) isEvent() {}
+
+type ServicesReadyEvent struct {
+	Identified bool
+}
+
+func (ServicesReadyEvent) isEvent() {}
+
+type QueryOpenedEvent struct {
+	Query *NetworkQuery
+}
+
+func (QueryOpenedEvent) isEvent() {}
+
+type DCCOfferEvent struct {
+	Transfer *DCCTransfer
+}
+
+func (DCCOfferEvent) isEvent() {}
+
+type TransferUpdatedEvent struct {
+	Transfer *DCCTransfer
+}
+
+func (TransferUpdatedEvent) isEvent() {}
+
+type DCCChatOfferEvent struct {
+	Chat *DCCChat
+}
+
+func (DCCChatOfferEvent) isEvent() {}
+
+type WhoisEvent struct {
+	Time   time.Time
+	Result WhoisResult
+}
+
+func (WhoisEvent) isEvent() {}
+
+type ISupportUpdatedEvent struct {
+	ISupport ISupport
+}
+
+func (ISupportUpdatedEvent) isEvent() {}
+
+type InviteEvent struct {
+	Time     time.Time
+	Inviter  string
+	Tag      string
+	AutoJoin bool
+}
+
+func (InviteEvent) isEvent() {}
+
+type JoinFailedEvent struct {
+	Time   time.Time
+	Tag    string
+	Reason string
+}
+
+func (JoinFailedEvent) isEvent() {}
+
+type AwayChangedEvent struct {
+	Away bool
+}
+
+func (AwayChangedEvent) isEvent() {}
+
+type LagUpdatedEvent struct {
+	Lag time.Duration
+}
+
+func (LagUpdatedEvent) isEvent() {}
+
+type SendFailedEvent struct {
+	Err     error
+	Dropped int
+}
+
+func (SendFailedEvent) isEvent() {}
+
+type ServiceNoticeEvent struct {
+	Time    time.Time
+	Service string
+	Content string
+}
+
+func (ServiceNoticeEvent) isEvent() {}
+
+type ChannelListEvent struct {
+	Time     time.Time
+	Channels []ListedChannel
+}
+
+func (ChannelListEvent) isEvent() {}
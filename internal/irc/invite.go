@@ -0,0 +1,112 @@
+package irc
+
+import (
+	"slices"
+	"time"
+)
+
+func (n *Network) shouldAutoJoin(tag string) bool {
+	invite := n.config.Invite
+	if !invite.AutoJoin {
+		return false
+	}
+	if len(invite.Channels) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(invite.Channels, func(channel string) bool {
+		return n.isSameName(channel, tag)
+	})
+}
+
+func (n *Network) handleInvite(msg inviteMessage, msgTime time.Time) {
+	inviter := msg.getSender()
+	if uorigin, ok := msg.origin.(userOrigin); ok {
+		inviter = uorigin.nickname
+	}
+	tag := msg.channelTag
+
+	if !n.hasNickname(msg.nickname) {
+		channel, ok := n.getChannel(tag)
+		if !ok {
+			return
+		}
+		channel.msgs <- ChannelMessage{
+			Time:    msgTime,
+			Content: inviter + " invited " + msg.nickname + " to the channel",
+		}
+		return
+	}
+
+	n.emitEvent(InviteEvent{
+		Time:     msgTime,
+		Inviter:  inviter,
+		Tag:      tag,
+		AutoJoin: n.shouldAutoJoin(tag),
+	})
+}
+
+func (n *Network) failJoin(channel *NetworkChannel, reason string, msgTime time.Time) {
+	channel.stopReceivingMsgs()
+	n.removeChannel(channel.tag)
+
+	n.emitEvent(JoinFailedEvent{
+		Time:   msgTime,
+		Tag:    channel.tag,
+		Reason: reason,
+	})
+}
+
+func (n *Network) handleInviteReply(msg replyMessage, msgTime time.Time) bool {
+	var (
+		tag     string
+		content string
+	)
+
+	switch msg.code {
+	case rpl_INVITING:
+		tag = msg.getParam(1)
+		content = "Invited " + msg.getParam(0) + " to " + tag
+	case rpl_KNOCK:
+		tag = msg.getParam(0)
+		content = msg.getParam(1) + " " + msg.getParam(2)
+	case rpl_KNOCKDLVR:
+		tag = msg.getParam(0)
+		content = "Knocked on " + tag
+	case err_TOOMANYKNOCK, err_CHANOPEN, err_KNOCKONCHAN:
+		tag = msg.getParam(0)
+		content = tag + ": " + msg.getParam(1)
+	default:
+		return false
+	}
+
+	if channel, ok := n.getChannel(tag); ok && channel.joined.Load() {
+		channel.msgs <- ChannelMessage{
+			Time:    msgTime,
+			Content: content,
+		}
+	} else {
+		n.msgs <- NetworkMessage{
+			Time:    msgTime,
+			Content: content,
+		}
+	}
+
+	return true
+}
+
+func (n *Network) Invite(nickname, tag string) error {
+	inviteMsg := inviteMessage{
+		nickname:   nickname,
+		channelTag: tag,
+	}
+	return n.conn.write(inviteMsg.encode())
+}
+
+func (n *Network) Knock(tag, content string) error {
+	knockMsg := knockMessage{
+		channelTag: tag,
+		content:    content,
+	}
+	return n.conn.write(knockMsg.encode())
+}
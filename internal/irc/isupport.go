@@ -0,0 +1,231 @@
+package irc
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+)
+
+type ISupport struct {
+	ChanTypes     string
+	PrefixModes   string
+	PrefixSymbols string
+	NickLen       int
+	ChannelLen    int
+	TopicLen      int
+	CaseMapping   string
+	ChanModes     [4]string
+	Network       string
+	MaxTargets    int
+	Modes         int
+	TargMax       map[string]int
+	WHOX          bool
+	EList         string
+	Monitor       bool
+}
+
+func DefaultISupport() ISupport {
+	return ISupport{
+		ChanTypes:     "#&",
+		PrefixModes:   "ov",
+		PrefixSymbols: "@+",
+		CaseMapping:   "rfc1459",
+		Modes:         3,
+		ChanModes:     [4]string{"b", "k", "l", "imnpst"},
+		TargMax:       map[string]int{},
+	}
+}
+
+func (is ISupport) clone() ISupport {
+	is.TargMax = maps.Clone(is.TargMax)
+
+	return is
+}
+
+func (is ISupport) IsChannel(target string) bool {
+	return target != "" && strings.ContainsRune(is.ChanTypes, rune(target[0]))
+}
+
+func (is ISupport) Fold(name string) string {
+	var upperLimit rune
+	switch is.CaseMapping {
+	case "ascii", "rfc7613":
+		upperLimit = 'Z'
+	case "strict-rfc1459":
+		upperLimit = ']'
+	default:
+		upperLimit = '^'
+	}
+
+	folded := strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= upperLimit {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, name)
+	if is.CaseMapping == "rfc7613" {
+		folded = strings.ToLower(folded)
+	}
+
+	return folded
+}
+
+func (is ISupport) MaxTargetsOf(command string) int {
+	if limit, ok := is.TargMax[strings.ToUpper(command)]; ok {
+		return limit
+	}
+
+	return is.MaxTargets
+}
+
+func unescapeISupportValue(value string) string {
+	if !strings.Contains(value, `\x`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+3 < len(value) && value[i+1] == 'x' {
+			if c, err := strconv.ParseUint(value[i+2:i+4], 16, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(value[i])
+	}
+
+	return b.String()
+}
+
+func parseISupportInt(value string, fallback int) int {
+	if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+		return parsed
+	}
+
+	return fallback
+}
+
+func (is *ISupport) apply(token string) {
+	defaults := DefaultISupport()
+
+	name, value, _ := strings.Cut(token, "=")
+	name, negated := strings.CutPrefix(name, "-")
+	value = unescapeISupportValue(value)
+
+	switch strings.ToUpper(name) {
+	case "CHANTYPES":
+		if negated {
+			is.ChanTypes = defaults.ChanTypes
+		} else {
+			is.ChanTypes = value
+		}
+	case "PREFIX":
+		modes, symbols, ok := strings.Cut(strings.TrimPrefix(value, "("), ")")
+		if negated || !ok || len(modes) != len(symbols) {
+			is.PrefixModes = defaults.PrefixModes
+			is.PrefixSymbols = defaults.PrefixSymbols
+		} else {
+			is.PrefixModes = modes
+			is.PrefixSymbols = symbols
+		}
+	case "NICKLEN":
+		is.NickLen = parseISupportInt(value, defaults.NickLen)
+	case "CHANNELLEN":
+		is.ChannelLen = parseISupportInt(value, defaults.ChannelLen)
+	case "TOPICLEN":
+		is.TopicLen = parseISupportInt(value, defaults.TopicLen)
+	case "CASEMAPPING":
+		if negated || value == "" {
+			is.CaseMapping = defaults.CaseMapping
+		} else {
+			is.CaseMapping = strings.ToLower(value)
+		}
+	case "CHANMODES":
+		is.ChanModes = defaults.ChanModes
+		if negated {
+			break
+		}
+		for i, modes := range strings.SplitN(value, ",", 4) {
+			is.ChanModes[i] = modes
+		}
+	case "MODES":
+		if !negated && value == "" {
+			is.Modes = 0
+		} else {
+			is.Modes = parseISupportInt(value, defaults.Modes)
+		}
+	case "NETWORK":
+		is.Network = value
+	case "MAXTARGETS":
+		is.MaxTargets = parseISupportInt(value, defaults.MaxTargets)
+	case "TARGMAX":
+		is.TargMax = map[string]int{}
+		if negated {
+			break
+		}
+		for target := range strings.SplitSeq(value, ",") {
+			command, limit, _ := strings.Cut(target, ":")
+			if command == "" {
+				continue
+			}
+			is.TargMax[strings.ToUpper(command)] = parseISupportInt(limit, 0)
+		}
+	case "WHOX":
+		is.WHOX = !negated
+	case "MONITOR":
+		is.Monitor = !negated
+	case "ELIST":
+		if negated {
+			is.EList = ""
+		} else {
+			is.EList = strings.ToUpper(value)
+		}
+	}
+}
+
+func (n *Network) handleISupport(tokens []string) {
+	n.imx.Lock()
+	caseMapping := n.isupport.CaseMapping
+	for _, token := range tokens {
+		n.isupport.apply(token)
+	}
+	isupport := n.isupport.clone()
+	n.imx.Unlock()
+
+	if caseMapping != isupport.CaseMapping {
+		n.refoldKeys()
+	}
+
+	n.emitEvent(ISupportUpdatedEvent{
+		ISupport: isupport,
+	})
+}
+
+func (n *Network) GetISupport() ISupport {
+	n.imx.Lock()
+	defer n.imx.Unlock()
+
+	return n.isupport.clone()
+}
+
+func (n *Network) Fold(name string) string {
+	n.imx.Lock()
+	defer n.imx.Unlock()
+
+	return n.isupport.Fold(name)
+}
+
+func (n *Network) isSameName(a, b string) bool {
+	n.imx.Lock()
+	defer n.imx.Unlock()
+
+	return n.isupport.Fold(a) == n.isupport.Fold(b)
+}
+
+func (n *Network) IsChannel(target string) bool {
+	n.imx.Lock()
+	defer n.imx.Unlock()
+
+	return n.isupport.IsChannel(target)
+}
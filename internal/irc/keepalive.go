@@ -0,0 +1,94 @@
+package irc
+
+import (
+	"log"
+	"strconv"
+	"time"
+)
+
+const lagTokenPrefix = "lag-"
+
+func (n *Network) extendReadDeadline() {
+	timeout := time.Duration(n.config.Ping.Timeout)
+	if timeout <= 0 {
+		return
+	}
+
+	if err := n.conn.setReadDeadline(time.Now().Add(timeout)); err != nil {
+		log.Printf("Failed to set read deadline: %v\n", err)
+	}
+}
+
+func (n *Network) sendLagPing() error {
+	now := time.Now()
+	token := lagTokenPrefix + strconv.FormatInt(now.UnixNano(), 10)
+
+	n.pmx.Lock()
+	if n.pingToken == "" {
+		n.pingToken = token
+		n.pingSent = now
+	} else {
+		token = n.pingToken
+	}
+	n.pmx.Unlock()
+
+	pingMsg := pingMessage{
+		token: token,
+	}
+	return n.conn.write(pingMsg.encode())
+}
+
+func (n *Network) startKeepalive() {
+	interval := time.Duration(n.config.Ping.Interval)
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-n.stopped:
+				return
+			case <-ticker.C:
+				if !n.IsRegistered() {
+					continue
+				}
+				if err := n.sendLagPing(); err != nil {
+					log.Printf("Failed to send ping: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+func (n *Network) handlePong(token string) {
+	n.pmx.Lock()
+	if token == "" || token != n.pingToken {
+		n.pmx.Unlock()
+		return
+	}
+	n.lag = time.Since(n.pingSent)
+	n.pingToken = ""
+	lag := n.lag
+	n.pmx.Unlock()
+
+	n.emitEvent(LagUpdatedEvent{
+		Lag: lag,
+	})
+}
+
+func (n *Network) GetLag() (time.Duration, bool) {
+	n.pmx.Lock()
+	defer n.pmx.Unlock()
+
+	if n.pingToken != "" {
+		if pending := time.Since(n.pingSent); pending > n.lag {
+			return pending, true
+		}
+	}
+
+	return n.lag, n.lag > 0
+}
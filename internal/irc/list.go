@@ -0,0 +1,54 @@
+package irc
+
+import (
+	"strconv"
+	"time"
+)
+
+type ListedChannel struct {
+	Tag   string
+	Users int
+	Topic string
+}
+
+func (n *Network) handleListReply(msg replyMessage, msgTime time.Time) bool {
+	switch msg.code {
+	case rpl_LISTSTART:
+		n.lmx.Lock()
+		n.listing = nil
+		n.lmx.Unlock()
+	case rpl_LIST:
+		users, _ := strconv.Atoi(msg.getParam(1))
+		n.lmx.Lock()
+		n.listing = append(n.listing, ListedChannel{
+			Tag:   msg.getParam(0),
+			Users: users,
+			Topic: msg.getParam(2),
+		})
+		n.lmx.Unlock()
+	case rpl_LISTEND:
+		n.lmx.Lock()
+		channels := n.listing
+		n.listing = nil
+		n.lmx.Unlock()
+		n.emitEvent(ChannelListEvent{
+			Time:     msgTime,
+			Channels: channels,
+		})
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (n *Network) List(filter string) error {
+	n.lmx.Lock()
+	n.listing = nil
+	n.lmx.Unlock()
+
+	listMsg := listMessage{
+		filter: filter,
+	}
+	return n.conn.write(listMsg.encode())
+}
@@ -0,0 +1,113 @@
+package irc
+
+import (
+	"slices"
+	"strings"
+)
+
+type ChannelMember struct {
+	Nickname string
+	User     string
+	Host     string
+	Prefixes string
+	Away     bool
+}
+
+func (cm ChannelMember) GetPrefix() string {
+	if cm.Prefixes == "" {
+		return ""
+	}
+
+	return cm.Prefixes[:1]
+}
+
+func parseNamesEntry(entry, prefixSymbols string) ChannelMember {
+	nickname := strings.TrimLeft(entry, prefixSymbols)
+	member := ChannelMember{
+		Prefixes: entry[:len(entry)-len(nickname)],
+	}
+
+	nickname, host, _ := strings.Cut(nickname, "@")
+	member.Nickname, member.User, _ = strings.Cut(nickname, "!")
+	member.Host = host
+
+	return member
+}
+
+func (nc *NetworkChannel) GetMembers() []ChannelMember {
+	isupport := nc.network.GetISupport()
+
+	nc.network.cmx.Lock()
+	members := make([]ChannelMember, 0, len(nc.users))
+	for _, member := range nc.users {
+		members = append(members, member.export())
+	}
+	nc.network.cmx.Unlock()
+
+	slices.SortFunc(members, func(a, b ChannelMember) int {
+		if rankA, rankB := isupport.prefixRank(a.Prefixes), isupport.prefixRank(b.Prefixes); rankA != rankB {
+			return rankA - rankB
+		}
+		return strings.Compare(isupport.Fold(a.Nickname), isupport.Fold(b.Nickname))
+	})
+
+	return members
+}
+
+func (nc *NetworkChannel) GetMember(nickname string) (ChannelMember, bool) {
+	nickname = nc.network.Fold(nickname)
+
+	nc.network.cmx.Lock()
+	defer nc.network.cmx.Unlock()
+
+	member, ok := nc.users[nickname]
+	if !ok {
+		return ChannelMember{}, false
+	}
+
+	return member.export(), true
+}
+
+func (nc *NetworkChannel) IsOperator(nickname string) bool {
+	member, ok := nc.GetMember(nickname)
+	if !ok {
+		return false
+	}
+
+	isupport := nc.network.GetISupport()
+	symbol, ok := isupport.PrefixSymbolOf('o')
+	if !ok {
+		return false
+	}
+
+	return isupport.prefixRank(member.Prefixes) <= strings.IndexByte(isupport.PrefixSymbols, symbol)
+}
+
+func (n *Network) updateMemberPrefixes(tag string, changes []ModeChange) {
+	isupport := n.GetISupport()
+	tag = isupport.Fold(tag)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	channel, ok := n.channels[tag]
+	if !ok {
+		return
+	}
+
+	for _, change := range changes {
+		symbol, ok := isupport.PrefixSymbolOf(change.Mode)
+		if !ok || change.Arg == "" {
+			continue
+		}
+		member, ok := channel.users[isupport.Fold(change.Arg)]
+		if !ok {
+			continue
+		}
+		if change.Adding {
+			member.prefixes = isupport.addPrefix(member.prefixes, symbol)
+		} else {
+			member.prefixes = removePrefix(member.prefixes, symbol)
+		}
+	}
+}
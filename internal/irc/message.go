@@ -1,9 +1,9 @@
 package irc
 
 import (
-	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -11,7 +11,7 @@ const (
 	rpl_YOURHOST      = 2
 	rpl_CREATED       = 3
 	rpl_MYINFO        = 4
-	rpl_BOUNCE        = 5
+	rpl_ISUPPORT      = 5
 	rpl_LUSERCLIENT   = 251
 	rpl_LUSEROP       = 252
 	rpl_LUSERUNKNOWN  = 253
@@ -21,16 +21,48 @@ const (
 	rpl_LUSERS        = 265
 	rpl_GUSERS        = 266
 	rpl_AWAY          = 301
+	rpl_USERHOST      = 302
+	rpl_UNAWAY        = 305
+	rpl_NOWAWAY       = 306
+	rpl_ENDOFWHO      = 315
+	rpl_WHOISUSER     = 311
+	rpl_WHOISSERVER   = 312
+	rpl_WHOISOPERATOR = 313
+	rpl_WHOWASUSER    = 314
+	rpl_WHOISCHANOP   = 316
+	rpl_WHOISIDLE     = 317
+	rpl_ENDOFWHOIS    = 318
+	rpl_WHOISCHANNELS = 319
+	rpl_WHOISACCOUNT  = 330
+	rpl_WHOISACTUALLY = 338
+	rpl_ENDOFWHOWAS   = 369
+	rpl_WHOISHOST     = 378
+	rpl_WHOISSECURE   = 671
+	rpl_LISTSTART     = 321
+	rpl_LIST          = 322
+	rpl_LISTEND       = 323
+	rpl_INVITING      = 341
+	rpl_KNOCK         = 710
+	rpl_KNOCKDLVR     = 711
+	rpl_CHANNELMODEIS = 324
+	rpl_CREATIONTIME  = 329
+	rpl_NOTOPIC       = 331
 	rpl_TOPIC         = 332
 	rpl_TOPICWHOTIME  = 333
+	rpl_WHOREPLY      = 352
 	rpl_NAMREPLY      = 353
+	rpl_WHOSPCRPL     = 354
 	rpl_ENDOFNAMES    = 366
 	rpl_MOTDSTART     = 375
 	rpl_MOTD          = 372
 	rpl_ENDOFMOTD     = 376
 	rpl_DHOST         = 396
 
+	err_NOSUCHNICK       = 401
 	err_NOSUCHCHANNEL    = 403
+	err_TOOMANYCHANNELS  = 405
+	err_WASNOSUCHNICK    = 406
+	err_UNKNOWNCOMMAND   = 421
 	err_NOMOTD           = 422
 	err_ERRONEUSNICKNAME = 432
 	err_NICKNAMEINUSE    = 433
@@ -38,15 +70,36 @@ const (
 	err_NOTONCHANNEL     = 442
 	err_NOTREGISTERED    = 451
 	err_ALREADYREGISTRED = 462
+	err_CHANNELISFULL    = 471
 	err_INVITEONLYCHAN   = 473
 	err_BANNEDFROMCHAN   = 474
+	err_BADCHANNELKEY    = 475
+	err_CHANOPRIVSNEEDED = 482
 	err_RESTRICTED       = 484
 	err_CANNOTSENDTOCHAN = 404
+	rpl_LOGGEDIN         = 900
+	rpl_LOGGEDOUT        = 901
+	err_NICKLOCKED       = 902
+	rpl_SASLSUCCESS      = 903
+	err_SASLFAIL         = 904
+	err_SASLTOOLONG      = 905
+	err_SASLABORTED      = 906
+	err_SASLALREADY      = 907
+	rpl_SASLMECHS        = 908
+	rpl_MONONLINE        = 730
+	rpl_MONOFFLINE       = 731
+	rpl_MONLIST          = 732
+	rpl_ENDOFMONLIST     = 733
+	err_MONLISTFULL      = 734
+	err_TOOMANYKNOCK     = 712
+	err_CHANOPEN         = 713
+	err_KNOCKONCHAN      = 714
 )
 
 type message interface {
 	getSender() string
 	getUnparsed() string
+	getServerTime() time.Time
 }
 
 type origin interface {
@@ -68,14 +121,16 @@ func (o serverOrigin) getSender() string {
 }
 
 type userOrigin struct {
-	nickname, identifier string
+	nickname, user, host string
 }
 
 func (o userOrigin) getSender() string {
-	return o.nickname + "!" + o.identifier
+	return Source{Name: o.nickname, User: o.user, Host: o.host}.String()
 }
 
 type baseMessage struct {
+	Message
+
 	origin   origin
 	original string
 }
@@ -88,6 +143,20 @@ func (m baseMessage) getUnparsed() string {
 	return m.original
 }
 
+func (m baseMessage) getServerTime() time.Time {
+	value, ok := m.GetTag("time")
+	if !ok {
+		return time.Time{}
+	}
+
+	serverTime, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return serverTime
+}
+
 type nickMessage struct {
 	baseMessage
 
@@ -95,7 +164,10 @@ type nickMessage struct {
 }
 
 func (m nickMessage) encode() []byte {
-	return fmt.Appendf([]byte{}, "NICK %s\r\n", m.nickname)
+	return Message{
+		Command: "NICK",
+		Params:  []string{m.nickname},
+	}.Encode()
 }
 
 type userMessage struct {
@@ -106,27 +178,45 @@ type userMessage struct {
 }
 
 func (m userMessage) encode() []byte {
-	return fmt.Appendf([]byte{}, "USER %s 0 * :%s\r\n", m.user, m.realname)
+	return Message{
+		Command: "USER",
+		Params:  []string{m.user, "0", "*", m.realname},
+	}.Encode()
 }
 
 type joinMessage struct {
 	baseMessage
 
-	channelTag string
+	channelTag, key   string
+	account, realname string
 }
 
 func (m joinMessage) encode() []byte {
-	return fmt.Appendf([]byte{}, "JOIN %s\r\n", m.channelTag)
+	params := []string{m.channelTag}
+	if m.key != "" {
+		params = append(params, m.key)
+	}
+	return Message{
+		Command: "JOIN",
+		Params:  params,
+	}.Encode()
 }
 
 type partMessage struct {
 	baseMessage
 
-	channelTag string
+	channelTag, reason string
 }
 
 func (m partMessage) encode() []byte {
-	return fmt.Appendf([]byte{}, "PART %s\r\n", m.channelTag)
+	params := []string{m.channelTag}
+	if m.reason != "" {
+		params = append(params, m.reason)
+	}
+	return Message{
+		Command: "PART",
+		Params:  params,
+	}.Encode()
 }
 
 type privMessage struct {
@@ -136,7 +226,10 @@ type privMessage struct {
 }
 
 func (m privMessage) encode() []byte {
-	return fmt.Appendf([]byte{}, "PRIVMSG %s :%s\r\n", m.target, m.content)
+	return Message{
+		Command: "PRIVMSG",
+		Params:  []string{m.target, m.content},
+	}.Encode()
 }
 
 type pongMessage struct {
@@ -146,7 +239,10 @@ type pongMessage struct {
 }
 
 func (m pongMessage) encode() []byte {
-	return fmt.Appendf([]byte{}, "PONG :%s\r\n", m.server)
+	return Message{
+		Command: "PONG",
+		Params:  []string{m.server},
+	}.Encode()
 }
 
 type quitMessage struct {
@@ -156,26 +252,220 @@ type quitMessage struct {
 }
 
 func (m quitMessage) encode() []byte {
-	raw := fmt.Appendf([]byte{}, "QUIT :%s\r\n", m.content)
-	return raw
+	return Message{
+		Command: "QUIT",
+		Params:  []string{m.content},
+	}.Encode()
 }
 
 type replyMessage struct {
 	baseMessage
 
-	target  string
-	code    uint16
-	content string
+	target string
+	code   uint16
+	params []string
+}
+
+func (m replyMessage) getContent() string {
+	return strings.Join(m.params, " ")
+}
+
+func (m replyMessage) getParam(index int) string {
+	if index < 0 || index >= len(m.params) {
+		return ""
+	}
+
+	return m.params[index]
 }
 
 type noticeMessage struct {
 	baseMessage
 
-	content string
+	target, content string
+}
+
+func (m noticeMessage) encode() []byte {
+	return Message{
+		Command: "NOTICE",
+		Params:  []string{m.target, m.content},
+	}.Encode()
 }
 
 type pingMessage struct {
 	baseMessage
+
+	token string
+}
+
+func (m pingMessage) encode() []byte {
+	return Message{
+		Command: "PING",
+		Params:  []string{m.token},
+	}.Encode()
+}
+
+type topicMessage struct {
+	baseMessage
+
+	channelTag, topic string
+	set               bool
+}
+
+func (m topicMessage) encode() []byte {
+	params := []string{m.channelTag}
+	if m.set {
+		params = append(params, m.topic)
+	}
+	return Message{
+		Command: "TOPIC",
+		Params:  params,
+	}.Encode()
+}
+
+type whoisMessage struct {
+	baseMessage
+
+	nickname string
+	whowas   bool
+}
+
+func (m whoisMessage) encode() []byte {
+	command := "WHOIS"
+	if m.whowas {
+		command = "WHOWAS"
+	}
+	return Message{
+		Command: command,
+		Params:  []string{m.nickname},
+	}.Encode()
+}
+
+type whoMessage struct {
+	baseMessage
+
+	mask, fields, token string
+}
+
+func (m whoMessage) encode() []byte {
+	params := []string{m.mask}
+	if m.fields != "" {
+		fields := m.fields
+		if m.token != "" {
+			fields += "," + m.token
+		}
+		params = append(params, fields)
+	}
+	return Message{
+		Command: "WHO",
+		Params:  params,
+	}.Encode()
+}
+
+type inviteMessage struct {
+	baseMessage
+
+	nickname, channelTag string
+}
+
+func (m inviteMessage) encode() []byte {
+	return Message{
+		Command: "INVITE",
+		Params:  []string{m.nickname, m.channelTag},
+	}.Encode()
+}
+
+type knockMessage struct {
+	baseMessage
+
+	channelTag, content string
+}
+
+func (m knockMessage) encode() []byte {
+	params := []string{m.channelTag}
+	if m.content != "" {
+		params = append(params, m.content)
+	}
+	return Message{
+		Command: "KNOCK",
+		Params:  params,
+	}.Encode()
+}
+
+type monitorMessage struct {
+	baseMessage
+
+	adding  bool
+	targets []string
+}
+
+func (m monitorMessage) encode() []byte {
+	modifier := "-"
+	if m.adding {
+		modifier = "+"
+	}
+	return Message{
+		Command: "MONITOR",
+		Params:  []string{modifier, strings.Join(m.targets, ",")},
+	}.Encode()
+}
+
+type listMessage struct {
+	baseMessage
+
+	filter string
+}
+
+func (m listMessage) encode() []byte {
+	params := []string{}
+	if m.filter != "" {
+		params = append(params, m.filter)
+	}
+	return Message{
+		Command: "LIST",
+		Params:  params,
+	}.Encode()
+}
+
+type awayMessage struct {
+	baseMessage
+
+	content string
+}
+
+func (m awayMessage) encode() []byte {
+	params := []string{}
+	if m.content != "" {
+		params = append(params, m.content)
+	}
+	return Message{
+		Command: "AWAY",
+		Params:  params,
+	}.Encode()
+}
+
+type chghostMessage struct {
+	baseMessage
+
+	user, host string
+}
+
+type accountMessage struct {
+	baseMessage
+
+	account string
+}
+
+type userhostMessage struct {
+	baseMessage
+
+	nickname string
+}
+
+func (m userhostMessage) encode() []byte {
+	return Message{
+		Command: "USERHOST",
+		Params:  []string{m.nickname},
+	}.Encode()
 }
 
 type kickMessage struct {
@@ -184,6 +474,17 @@ type kickMessage struct {
 	channelTag, nickname, reason string
 }
 
+func (m kickMessage) encode() []byte {
+	params := []string{m.channelTag, m.nickname}
+	if m.reason != "" {
+		params = append(params, m.reason)
+	}
+	return Message{
+		Command: "KICK",
+		Params:  params,
+	}.Encode()
+}
+
 type errorMessage struct {
 	baseMessage
 
@@ -194,120 +495,231 @@ type modeMessage struct {
 	baseMessage
 
 	target, modes string
+	args          []string
+}
+
+func (m modeMessage) encode() []byte {
+	params := []string{m.target}
+	if m.modes != "" {
+		params = append(params, m.modes)
+		params = append(params, m.args...)
+	}
+	return Message{
+		Command: "MODE",
+		Params:  params,
+	}.Encode()
+}
+
+type capMessage struct {
+	baseMessage
+
+	target, subcommand string
+	version            string
+	multiline          bool
+	caps               []string
+}
+
+func (m capMessage) encode() []byte {
+	params := []string{m.subcommand}
+	if m.version != "" {
+		params = append(params, m.version)
+	}
+	if len(m.caps) > 0 {
+		params = append(params, strings.Join(m.caps, " "))
+	}
+	return Message{
+		Command: "CAP",
+		Params:  params,
+	}.Encode()
+}
+
+type authenticateMessage struct {
+	baseMessage
+
+	payload string
+}
+
+func (m authenticateMessage) encode() []byte {
+	return Message{
+		Command: "AUTHENTICATE",
+		Params:  []string{m.payload},
+	}.Encode()
 }
 
 type unknownMessage struct {
 	baseMessage
 }
 
+func newOrigin(source *Source) origin {
+	switch {
+	case source == nil:
+		return withoutOrigin{}
+	case source.IsServer():
+		return serverOrigin{
+			servername: source.Name,
+		}
+	default:
+		return userOrigin{
+			nickname: source.Name,
+			user:     source.User,
+			host:     source.Host,
+		}
+	}
+}
+
 func decodeMessage(raw []byte) message {
 	sraw := string(raw)
+
+	parsed, err := ParseMessage(sraw)
 	baseMsg := baseMessage{
-		origin:   withoutOrigin{},
+		Message:  parsed,
+		origin:   newOrigin(parsed.Source),
 		original: sraw,
 	}
-
-	var parts []string
-	if raw[0] == ':' {
-		parts = strings.SplitN(sraw[1:], " ", 3)
-		var origin origin
-		if nickname, identifier, found := strings.Cut(parts[0], "!"); found {
-			origin = userOrigin{
-				nickname:   nickname,
-				identifier: identifier,
-			}
-		} else {
-			origin = serverOrigin{
-				servername: parts[0],
-			}
+	if err != nil {
+		return unknownMessage{
+			baseMessage: baseMsg,
 		}
-		baseMsg.origin = origin
-		parts = parts[1:]
-	} else {
-		parts = strings.SplitN(sraw, " ", 2)
 	}
 
-	if len(parts[0]) == 3 {
-		if code, err := strconv.ParseUint(parts[0], 10, 16); err == nil {
-			parts = strings.SplitN(parts[1], " ", 2)
-			parts[1] = strings.TrimPrefix(parts[1], ":")
-			return replyMessage{
+	params := parsed.Params
+	param := parsed.GetParam
+
+	if len(parsed.Command) == 3 {
+		if code, err := strconv.ParseUint(parsed.Command, 10, 16); err == nil {
+			replyMsg := replyMessage{
 				baseMessage: baseMsg,
-				target:      parts[0],
+				target:      param(0),
 				code:        uint16(code),
-				content:     parts[1],
+				params:      []string{},
 			}
+			if len(params) > 1 {
+				replyMsg.params = params[1:]
+			}
+			return replyMsg
 		}
 	}
 
 	var msg message
-	switch parts[0] {
+	switch parsed.Command {
 	case "NICK":
-		parts[1] = strings.TrimPrefix(parts[1], ":")
 		msg = nickMessage{
 			baseMessage: baseMsg,
-			nickname:    parts[1],
+			nickname:    param(0),
 		}
 	case "JOIN":
-		msg = joinMessage{
+		joinMsg := joinMessage{
 			baseMessage: baseMsg,
-			channelTag:  parts[1],
+			channelTag:  param(0),
 		}
+		if len(params) > 2 {
+			if account := param(1); account != "*" {
+				joinMsg.account = account
+			}
+			joinMsg.realname = param(2)
+		}
+		msg = joinMsg
 	case "PART":
 		msg = partMessage{
 			baseMessage: baseMsg,
-			channelTag:  parts[1],
+			channelTag:  param(0),
+			reason:      param(1),
 		}
 	case "PRIVMSG":
-		parts = strings.SplitN(parts[1], " ", 2)
-		parts[1] = strings.TrimPrefix(parts[1], ":")
 		msg = privMessage{
 			baseMessage: baseMsg,
-			target:      parts[0],
-			content:     parts[1],
+			target:      param(0),
+			content:     param(1),
 		}
 	case "QUIT":
 		msg = quitMessage{
 			baseMessage: baseMsg,
+			content:     param(0),
 		}
 	case "NOTICE":
-		parts = strings.SplitN(parts[1], " ", 2)
-		parts[1] = strings.TrimPrefix(parts[1], ":")
 		msg = noticeMessage{
 			baseMessage: baseMsg,
-			content:     parts[1],
+			target:      param(0),
+			content:     param(1),
 		}
 	case "KICK":
-		parts = strings.SplitN(parts[1], " ", 3)
-		kickMsg := kickMessage{
+		msg = kickMessage{
 			baseMessage: baseMsg,
-			channelTag:  parts[0],
-			nickname:    parts[1],
+			channelTag:  param(0),
+			nickname:    param(1),
+			reason:      param(2),
 		}
-		if len(parts) == 3 {
-			kickMsg.reason = parts[2][1:]
+	case "TOPIC":
+		msg = topicMessage{
+			baseMessage: baseMsg,
+			channelTag:  param(0),
+			topic:       param(1),
+			set:         len(params) > 1,
 		}
-		msg = kickMsg
-	case "PING":
-		baseMsg.origin = serverOrigin{
-			servername: parts[1][1:],
+	case "INVITE":
+		msg = inviteMessage{
+			baseMessage: baseMsg,
+			nickname:    param(0),
+			channelTag:  param(1),
 		}
+	case "AWAY":
+		msg = awayMessage{
+			baseMessage: baseMsg,
+			content:     param(0),
+		}
+	case "CHGHOST":
+		msg = chghostMessage{
+			baseMessage: baseMsg,
+			user:        param(0),
+			host:        param(1),
+		}
+	case "ACCOUNT":
+		accountMsg := accountMessage{
+			baseMessage: baseMsg,
+		}
+		if account := param(0); account != "*" {
+			accountMsg.account = account
+		}
+		msg = accountMsg
+	case "PONG":
+		msg = pongMessage{
+			baseMessage: baseMsg,
+			server:      parsed.GetTrailing(),
+		}
+	case "PING":
 		msg = pingMessage{
 			baseMessage: baseMsg,
+			token:       parsed.GetTrailing(),
 		}
 	case "ERROR":
-		parts[1] = strings.TrimPrefix(parts[1], ":")
 		msg = errorMessage{
 			baseMessage: baseMsg,
-			content:     parts[1],
+			content:     param(0),
 		}
 	case "MODE":
-		parts = strings.SplitN(parts[1], " ", 2)
-		parts[1] = strings.TrimPrefix(parts[1], ":")
-		msg = modeMessage{
+		modeMsg := modeMessage{
 			baseMessage: baseMsg,
-			target:      parts[0],
-			modes:       parts[1],
+			target:      param(0),
+			modes:       param(1),
+			args:        []string{},
+		}
+		if len(params) > 2 {
+			modeMsg.args = params[2:]
+		}
+		msg = modeMsg
+	case "CAP":
+		capMsg := capMessage{
+			baseMessage: baseMsg,
+			target:      param(0),
+			subcommand:  strings.ToUpper(param(1)),
+			multiline:   len(params) > 3 && param(2) == "*",
+		}
+		capMsg.caps = strings.Fields(parsed.GetTrailing())
+		msg = capMsg
+	case "AUTHENTICATE":
+		msg = authenticateMessage{
+			baseMessage: baseMsg,
+			payload:     param(0),
 		}
 	default:
 		msg = unknownMessage{
@@ -319,10 +731,20 @@ func decodeMessage(raw []byte) message {
 }
 
 type NetworkMessage struct {
+	Time    time.Time
 	Content string
 }
 
+type MessageKind int
+
+const (
+	TextMessage MessageKind = iota
+	ActionMessage
+)
+
 type ChannelMessage struct {
+	Time    time.Time
+	Kind    MessageKind
 	Sender  string
 	Content string
 }
@@ -0,0 +1,231 @@
+package irc
+
+import (
+	"log"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/franciscosbf/irc-client/internal/config"
+)
+
+type pendingBan struct {
+	tag      string
+	nickname string
+	adding   bool
+	kick     bool
+	reason   string
+}
+
+func domainMask(host string) string {
+	if ip := net.ParseIP(host); ip != nil {
+		separator := "."
+		if ip.To4() == nil {
+			separator = ":"
+		}
+		return "*!*@" + host[:strings.LastIndex(host, separator)+1] + "*"
+	}
+
+	if _, domain, found := strings.Cut(host, "."); found && strings.Contains(domain, ".") {
+		return "*!*@*." + domain
+	}
+
+	return "*!*@" + host
+}
+
+func buildBanMask(member ChannelMember, style string) string {
+	if member.Host == "" {
+		return member.Nickname + "!*@*"
+	}
+
+	user := strings.TrimPrefix(member.User, "~")
+	if user == "" {
+		user = "*"
+	} else if user != member.User {
+		user = "*" + user
+	}
+
+	switch style {
+	case config.BanMaskNick:
+		return member.Nickname + "!*@*"
+	case config.BanMaskUserHost:
+		return "*!" + user + "@" + member.Host
+	case config.BanMaskDomain:
+		return domainMask(member.Host)
+	case config.BanMaskExact:
+		return member.Nickname + "!" + user + "@" + member.Host
+	default:
+		return "*!*@" + member.Host
+	}
+}
+
+func parseUserhostEntry(entry string) ChannelMember {
+	nickname, userhost, _ := strings.Cut(entry, "=")
+	user, host, _ := strings.Cut(strings.TrimLeft(userhost, "+-"), "@")
+
+	return ChannelMember{
+		Nickname: strings.TrimSuffix(nickname, "*"),
+		User:     user,
+		Host:     host,
+	}
+}
+
+func (n *Network) findBanTarget(tag, target string) ChannelMember {
+	member := ChannelMember{
+		Nickname: target,
+	}
+	if channel, ok := n.getChannel(tag); ok {
+		if known, ok := channel.GetMember(target); ok {
+			member = known
+		}
+	}
+	if member.Host == "" {
+		if user, ok := n.GetUser(target); ok {
+			member.User = user.Username
+			member.Host = user.Host
+		}
+	}
+
+	return member
+}
+
+func (n *Network) BanMask(tag, target string) string {
+	if strings.ContainsAny(target, "!@") {
+		return target
+	}
+
+	return buildBanMask(n.findBanTarget(tag, target), n.config.Ban.MaskStyle)
+}
+
+func (n *Network) sendBan(ban *pendingBan, mask string) error {
+	if err := n.SetChannelModes(ban.tag, ban.adding, 'b', []string{mask}); err != nil {
+		return err
+	}
+	if !ban.kick {
+		return nil
+	}
+
+	return n.Kick(ban.tag, ban.nickname, ban.reason)
+}
+
+func (n *Network) removePendingBan(ban *pendingBan) {
+	n.bmx.Lock()
+	defer n.bmx.Unlock()
+
+	n.pendingBans = slices.DeleteFunc(n.pendingBans, func(pending *pendingBan) bool {
+		return pending == ban
+	})
+}
+
+func (n *Network) ban(ban *pendingBan) error {
+	if strings.ContainsAny(ban.nickname, "!@") || n.findBanTarget(ban.tag, ban.nickname).Host != "" {
+		return n.sendBan(ban, n.BanMask(ban.tag, ban.nickname))
+	}
+
+	n.bmx.Lock()
+	n.pendingBans = append(n.pendingBans, ban)
+	n.bmx.Unlock()
+
+	userhostMsg := userhostMessage{
+		nickname: ban.nickname,
+	}
+	if err := n.conn.write(userhostMsg.encode()); err != nil {
+		n.removePendingBan(ban)
+		return err
+	}
+
+	return nil
+}
+
+func (n *Network) handleBanReply(msg replyMessage) bool {
+	if msg.code != rpl_USERHOST {
+		return false
+	}
+
+	n.bmx.Lock()
+	if len(n.pendingBans) == 0 {
+		n.bmx.Unlock()
+		return false
+	}
+	ban := n.pendingBans[0]
+	n.pendingBans = n.pendingBans[1:]
+	n.bmx.Unlock()
+
+	member := ChannelMember{
+		Nickname: ban.nickname,
+	}
+	for entry := range strings.FieldsSeq(msg.getParam(len(msg.params) - 1)) {
+		if found := parseUserhostEntry(entry); n.isSameName(found.Nickname, ban.nickname) {
+			member = found
+			n.updateUser(found.Nickname, func(info *UserInfo) {
+				info.Username = found.User
+				info.Host = found.Host
+			})
+			break
+		}
+	}
+
+	if err := n.sendBan(ban, buildBanMask(member, n.config.Ban.MaskStyle)); err != nil {
+		log.Printf("Failed to ban %s from %s: %v\n", ban.nickname, ban.tag, err)
+	}
+
+	return true
+}
+
+func (n *Network) Kick(tag, nickname, reason string) error {
+	kickMsg := kickMessage{
+		channelTag: tag,
+		nickname:   nickname,
+		reason:     reason,
+	}
+	return n.conn.write(kickMsg.encode())
+}
+
+func (n *Network) SetChannelModes(tag string, adding bool, mode byte, args []string) error {
+	sign := "+"
+	if !adding {
+		sign = "-"
+	}
+
+	perMessage := n.GetISupport().Modes
+	if perMessage <= 0 {
+		perMessage = len(args)
+	}
+
+	for len(args) > 0 {
+		chunk := args[:min(perMessage, len(args))]
+		args = args[len(chunk):]
+
+		modes := sign + strings.Repeat(string(mode), len(chunk))
+		if err := n.SetMode(tag, modes, chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *Network) Ban(tag, target string) error {
+	return n.ban(&pendingBan{
+		tag:      tag,
+		nickname: target,
+		adding:   true,
+	})
+}
+
+func (n *Network) Unban(tag, target string) error {
+	return n.ban(&pendingBan{
+		tag:      tag,
+		nickname: target,
+	})
+}
+
+func (n *Network) KickBan(tag, nickname, reason string) error {
+	return n.ban(&pendingBan{
+		tag:      tag,
+		nickname: nickname,
+		adding:   true,
+		kick:     true,
+		reason:   reason,
+	})
+}
@@ -0,0 +1,199 @@
+package irc
+
+import (
+	"slices"
+	"strings"
+)
+
+type ModeChange struct {
+	Adding bool
+	Mode   byte
+	Arg    string
+}
+
+func (is ISupport) modeTakesArg(mode byte, adding bool) bool {
+	switch {
+	case strings.IndexByte(is.PrefixModes, mode) >= 0:
+		return true
+	case strings.IndexByte(is.ChanModes[0], mode) >= 0:
+		return true
+	case strings.IndexByte(is.ChanModes[1], mode) >= 0:
+		return true
+	case strings.IndexByte(is.ChanModes[2], mode) >= 0:
+		return adding
+	default:
+		return false
+	}
+}
+
+func (is ISupport) ParseModes(modes string, args []string) []ModeChange {
+	changes := []ModeChange{}
+
+	adding := true
+	for i := 0; i < len(modes); i++ {
+		switch mode := modes[i]; mode {
+		case '+':
+			adding = true
+		case '-':
+			adding = false
+		default:
+			change := ModeChange{
+				Adding: adding,
+				Mode:   mode,
+			}
+			if is.modeTakesArg(mode, adding) && len(args) > 0 {
+				change.Arg = args[0]
+				args = args[1:]
+			}
+			changes = append(changes, change)
+		}
+	}
+
+	return changes
+}
+
+func (is ISupport) PrefixSymbolOf(mode byte) (byte, bool) {
+	i := strings.IndexByte(is.PrefixModes, mode)
+	if i < 0 || i >= len(is.PrefixSymbols) {
+		return 0, false
+	}
+
+	return is.PrefixSymbols[i], true
+}
+
+func (is ISupport) prefixRank(prefixes string) int {
+	if prefixes == "" {
+		return len(is.PrefixSymbols)
+	}
+
+	if rank := strings.IndexByte(is.PrefixSymbols, prefixes[0]); rank >= 0 {
+		return rank
+	}
+
+	return len(is.PrefixSymbols)
+}
+
+func (is ISupport) addPrefix(prefixes string, symbol byte) string {
+	if strings.IndexByte(prefixes, symbol) >= 0 {
+		return prefixes
+	}
+
+	var sorted strings.Builder
+	for i := 0; i < len(is.PrefixSymbols); i++ {
+		if s := is.PrefixSymbols[i]; s == symbol || strings.IndexByte(prefixes, s) >= 0 {
+			sorted.WriteByte(s)
+		}
+	}
+
+	return sorted.String()
+}
+
+func removePrefix(prefixes string, symbol byte) string {
+	return strings.ReplaceAll(prefixes, string(symbol), "")
+}
+
+func (mc ModeChange) String() string {
+	sign := "+"
+	if !mc.Adding {
+		sign = "-"
+	}
+
+	if mc.Arg == "" {
+		return sign + string(mc.Mode)
+	}
+
+	return sign + string(mc.Mode) + " " + mc.Arg
+}
+
+func (is ISupport) isChannelStateMode(mode byte) bool {
+	return strings.IndexByte(is.PrefixModes, mode) < 0 && strings.IndexByte(is.ChanModes[0], mode) < 0
+}
+
+func (nc *NetworkChannel) GetModes() string {
+	nc.network.cmx.Lock()
+	defer nc.network.cmx.Unlock()
+
+	if len(nc.modes) == 0 {
+		return ""
+	}
+
+	modes := make([]byte, 0, len(nc.modes))
+	for mode := range nc.modes {
+		modes = append(modes, mode)
+	}
+	slices.Sort(modes)
+
+	args := []string{}
+	for _, mode := range modes {
+		if arg := nc.modes[mode]; arg != "" {
+			args = append(args, arg)
+		}
+	}
+
+	return strings.Join(append([]string{"+" + string(modes)}, args...), " ")
+}
+
+func (nc *NetworkChannel) GetMode(mode byte) (string, bool) {
+	nc.network.cmx.Lock()
+	defer nc.network.cmx.Unlock()
+
+	arg, ok := nc.modes[mode]
+	return arg, ok
+}
+
+func (nc *NetworkChannel) GetKey() string {
+	if key, ok := nc.GetMode('k'); ok && key != "" && key != "*" {
+		return key
+	}
+
+	return nc.key
+}
+
+func (n *Network) updateChannelModes(tag string, changes []ModeChange, reset bool) (*NetworkChannel, bool) {
+	isupport := n.GetISupport()
+
+	n.updateMemberPrefixes(tag, changes)
+
+	tag = isupport.Fold(tag)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	channel, ok := n.channels[tag]
+	if !ok {
+		return nil, false
+	}
+
+	if reset {
+		channel.modes = map[byte]string{}
+	}
+
+	for _, change := range changes {
+		if !isupport.isChannelStateMode(change.Mode) {
+			continue
+		}
+		if change.Adding {
+			channel.modes[change.Mode] = change.Arg
+		} else {
+			delete(channel.modes, change.Mode)
+		}
+	}
+
+	return channel, true
+}
+
+func (n *Network) requestChannelModes(tag string) error {
+	modeMsg := modeMessage{
+		target: tag,
+	}
+	return n.conn.write(modeMsg.encode())
+}
+
+func (n *Network) SetMode(target, modes string, args []string) error {
+	modeMsg := modeMessage{
+		target: target,
+		modes:  modes,
+		args:   args,
+	}
+	return n.conn.write(modeMsg.encode())
+}
@@ -8,24 +8,29 @@ import (
 	"io"
 	"log"
 	"net"
+	"os"
 	"os/user"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/franciscosbf/irc-client/internal/config"
 )
 
 const (
 	networkPort           = "6667"
 	networkTlsPort        = "6697"
-	readerBufSize         = 520
+	readerBufSize         = 8191 + 512
 	messagesBufSize       = 32
 	dialConnectionTimeout = time.Second * 4
 )
 
 type Connection interface {
 	getHost() string
+	localAddr() net.Addr
 	read() ([]byte, bool, error)
+	setReadDeadline(t time.Time) error
 	write(b []byte) error
 	close()
 }
@@ -45,10 +50,18 @@ func (nc *NetworkConnection) getHost() string {
 	return nc.host
 }
 
+func (nc *NetworkConnection) localAddr() net.Addr {
+	return nc.conn.LocalAddr()
+}
+
 func (nc *NetworkConnection) read() ([]byte, bool, error) {
 	return nc.reader.ReadLine()
 }
 
+func (nc *NetworkConnection) setReadDeadline(t time.Time) error {
+	return nc.conn.SetReadDeadline(t)
+}
+
 func (nc *NetworkConnection) write(b []byte) error {
 	_, err := nc.conn.Write(b)
 
@@ -59,7 +72,16 @@ func (nc *NetworkConnection) close() {
 	_ = nc.conn.Close()
 }
 
-func DialNetworkConnection(host string) (*NetworkConnection, error) {
+func newNetworkConnection(host string, conn net.Conn, secure bool) *NetworkConnection {
+	return &NetworkConnection{
+		secure: secure,
+		host:   host,
+		conn:   conn,
+		reader: bufio.NewReaderSize(conn, readerBufSize),
+	}
+}
+
+func DialNetworkConnection(host string, config config.Network) (*NetworkConnection, error) {
 	var (
 		secure bool
 		conn   net.Conn
@@ -71,9 +93,20 @@ func DialNetworkConnection(host string) (*NetworkConnection, error) {
 	}
 	tlsDialer := tls.Dialer{
 		NetDialer: &netDialer,
+		Config:    &tls.Config{},
+	}
+	if config.ClientCert.IsSet() {
+		cert, err := LoadClientCertificate(config.ClientCert)
+		if err != nil {
+			return nil, err
+		}
+		tlsDialer.Config.Certificates = []tls.Certificate{cert}
 	}
 	tlsAddr := net.JoinHostPort(host, networkTlsPort)
 	if conn, err = tlsDialer.Dial("tcp4", tlsAddr); err != nil {
+		if config.ClientCert.IsSet() {
+			return nil, fmt.Errorf("client certificate requires TLS: %v", err)
+		}
 		addr := net.JoinHostPort(host, networkPort)
 		if conn, err = netDialer.Dial("tcp4", addr); err != nil {
 			return nil, err
@@ -82,21 +115,20 @@ func DialNetworkConnection(host string) (*NetworkConnection, error) {
 		secure = true
 	}
 
-	return &NetworkConnection{
-		secure: secure,
-		host:   host,
-		conn:   conn,
-		reader: bufio.NewReaderSize(conn, readerBufSize),
-	}, nil
+	return newNetworkConnection(host, conn, secure), nil
 }
 
 type NetworkChannel struct {
 	tag        string
+	key        string
 	closed     atomic.Bool
+	joined     atomic.Bool
 	noMoreMsgs chan struct{}
 	msgs       chan ChannelMessage
 	network    *Network
-	users      map[string]struct{}
+	users      map[string]*channelMember
+	modes      map[byte]string
+	topic      ChannelTopic
 }
 
 func (nc *NetworkChannel) signalNoMoreMsgs() {
@@ -124,6 +156,10 @@ func (nc *NetworkChannel) SendMessage(content string) error {
 	return nc.network.conn.write(privMsg.encode())
 }
 
+func (nc *NetworkChannel) SendAction(content string) error {
+	return nc.network.sendAction(nc.tag, content)
+}
+
 func (nc *NetworkChannel) ReceiveMessage() (ChannelMessage, bool) {
 	if nc.closed.Load() {
 		return ChannelMessage{}, false
@@ -155,35 +191,79 @@ func (nc *NetworkChannel) Part() error {
 }
 
 type Network struct {
-	registered atomic.Bool
+	registered  atomic.Bool
+	ready       atomic.Bool
+	loggedIn    atomic.Bool
+	identifying atomic.Bool
+
+	smx           sync.Mutex
+	identifyTimer *time.Timer
+
+	caps   capabilities
+	config config.Network
+
+	nmx              sync.Mutex
+	nickname         string
+	primaryNickname  string
+	nicknameAttempts int
+
+	away        atomic.Bool
+	awayMessage string
 
-	nmx      sync.Mutex
-	nickname string
+	pmx       sync.Mutex
+	pingToken string
+	pingSent  time.Time
+	lag       time.Duration
+	stopped   chan struct{}
 
 	listenerStarted bool
 	conn            Connection
+	queue           *sendQueue
 	msgs            chan NetworkMessage
 
-	cmx           sync.Mutex
-	channels      map[string]*NetworkChannel
-	usersChannels map[string]map[string]*NetworkChannel
+	emx          sync.Mutex
+	events       chan Event
+	eventsClosed bool
+	eventsDone   chan struct{}
+	eventsOnce   sync.Once
+
+	imx      sync.Mutex
+	isupport ISupport
+
+	wmx        sync.Mutex
+	whois      map[string]*WhoisResult
+	pendingWho map[string]struct{}
+
+	lmx     sync.Mutex
+	listing []ListedChannel
+
+	bmx         sync.Mutex
+	pendingBans []*pendingBan
+
+	dccTransport dccTransport
+	transfers    dccTransfers
+	dccChats     dccChats
+
+	cmx      sync.Mutex
+	channels map[string]*NetworkChannel
+	users    map[string]*networkUser
+	queries  map[string]*NetworkQuery
 }
 
 func (n *Network) closeAndCleanup() {
+	n.stopIdentifyTimer()
 	n.conn.close()
 
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
 	n.channels = nil
-	n.usersChannels = nil
+	n.users = nil
+	n.queries = nil
 }
 
 func (n *Network) hasNickname(nickname string) bool {
-	n.nmx.Lock()
-	defer n.nmx.Unlock()
-
-	return n.nickname == nickname
+	return n.isSameName(n.getNickname(), nickname)
 }
 
 func (n *Network) setNickname(nickname string) {
@@ -197,7 +277,7 @@ func (n *Network) replaceNickname(oldNickName, newNickname string) bool {
 	n.nmx.Lock()
 	defer n.nmx.Unlock()
 
-	if oldNickName != n.nickname {
+	if !n.isSameName(oldNickName, n.nickname) {
 		return false
 	}
 
@@ -213,19 +293,53 @@ func (n *Network) getNickname() string {
 	return n.nickname
 }
 
+func (n *Network) refoldKeys() {
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	users := map[string]*networkUser{}
+	for _, user := range n.users {
+		users[n.Fold(user.info.Nickname)] = user
+		user.channels = map[string]*NetworkChannel{}
+	}
+	n.users = users
+
+	channels := map[string]*NetworkChannel{}
+	for _, channel := range n.channels {
+		tag := n.Fold(channel.tag)
+		channels[tag] = channel
+
+		members := map[string]*channelMember{}
+		for _, member := range channel.users {
+			members[n.Fold(member.user.info.Nickname)] = member
+			member.user.channels[tag] = channel
+		}
+		channel.users = members
+	}
+	n.channels = channels
+
+	queries := map[string]*NetworkQuery{}
+	for _, query := range n.queries {
+		queries[n.Fold(query.GetTag())] = query
+	}
+	n.queries = queries
+}
+
 func (n *Network) removeUser(nickname string) []*NetworkChannel {
+	nickname = n.Fold(nickname)
+
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
-	userChannels, ok := n.usersChannels[nickname]
+	user, ok := n.users[nickname]
 	if !ok {
 		return nil
 	}
 
-	delete(n.usersChannels, nickname)
+	delete(n.users, nickname)
 
 	channels := []*NetworkChannel{}
-	for _, channel := range userChannels {
+	for _, channel := range user.channels {
 		delete(channel.users, nickname)
 		channels = append(channels, channel)
 	}
@@ -234,6 +348,8 @@ func (n *Network) removeUser(nickname string) []*NetworkChannel {
 }
 
 func (n *Network) addChannel(tag string, channel *NetworkChannel) {
+	tag = n.Fold(tag)
+
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
@@ -241,6 +357,8 @@ func (n *Network) addChannel(tag string, channel *NetworkChannel) {
 }
 
 func (n *Network) removeChannel(tag string) {
+	tag = n.Fold(tag)
+
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
@@ -251,12 +369,17 @@ func (n *Network) removeChannel(tag string) {
 
 	delete(n.channels, tag)
 
-	for nickname := range channel.users {
-		delete(n.usersChannels, nickname)
+	for nickname, member := range channel.users {
+		delete(member.user.channels, tag)
+		if len(member.user.channels) == 0 {
+			delete(n.users, nickname)
+		}
 	}
 }
 
 func (n *Network) getChannel(tag string) (*NetworkChannel, bool) {
+	tag = n.Fold(tag)
+
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
@@ -276,7 +399,9 @@ func (n *Network) getChannels() []*NetworkChannel {
 	return channels
 }
 
-func (n *Network) addChannelUsers(nicknames []string, tag string) (*NetworkChannel, bool) {
+func (n *Network) addChannelUsers(members []ChannelMember, tag string) (*NetworkChannel, bool) {
+	tag = n.Fold(tag)
+
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
@@ -285,21 +410,40 @@ func (n *Network) addChannelUsers(nicknames []string, tag string) (*NetworkChann
 		return nil, false
 	}
 
-	for _, nickname := range nicknames {
-		channel.users[nickname] = struct{}{}
+	for _, member := range members {
+		nickname := n.Fold(member.Nickname)
 
-		userChannels, ok := n.usersChannels[nickname]
+		user, ok := n.users[nickname]
 		if !ok {
-			userChannels = map[string]*NetworkChannel{}
-			n.usersChannels[nickname] = userChannels
+			user = &networkUser{
+				channels: map[string]*NetworkChannel{},
+			}
+			n.users[nickname] = user
+		}
+		user.info.merge(UserInfo{
+			Nickname: member.Nickname,
+			Username: member.User,
+			Host:     member.Host,
+		})
+		user.channels[tag] = channel
+
+		if current, ok := channel.users[nickname]; ok {
+			current.prefixes = member.Prefixes
+		} else {
+			channel.users[nickname] = &channelMember{
+				user:     user,
+				prefixes: member.Prefixes,
+			}
 		}
-		userChannels[tag] = channel
 	}
 
 	return channel, true
 }
 
 func (n *Network) removeChannelUser(nickname, tag string) (*NetworkChannel, bool) {
+	nickname = n.Fold(nickname)
+	tag = n.Fold(tag)
+
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
@@ -308,8 +452,11 @@ func (n *Network) removeChannelUser(nickname, tag string) (*NetworkChannel, bool
 		return nil, false
 	}
 
-	if userChannels, ok := n.usersChannels[nickname]; ok {
-		delete(userChannels, tag)
+	if user, ok := n.users[nickname]; ok {
+		delete(user.channels, tag)
+		if len(user.channels) == 0 {
+			delete(n.users, nickname)
+		}
 	}
 
 	delete(channel.users, nickname)
@@ -318,29 +465,56 @@ func (n *Network) removeChannelUser(nickname, tag string) (*NetworkChannel, bool
 }
 
 func (n *Network) replaceUser(oldNickName, newNickname string) []*NetworkChannel {
+	oldKey := n.Fold(oldNickName)
+	newKey := n.Fold(newNickname)
+
 	n.cmx.Lock()
 	defer n.cmx.Unlock()
 
 	channels := []*NetworkChannel{}
 
-	userChannels, ok := n.usersChannels[oldNickName]
-	if ok {
-		for _, channel := range userChannels {
-			delete(channel.users, oldNickName)
-			channel.users[newNickname] = struct{}{}
-			channels = append(channels, channel)
-		}
-	} else {
-		userChannels = map[string]*NetworkChannel{}
+	user, ok := n.users[oldKey]
+	if !ok {
+		return channels
 	}
-	n.usersChannels[newNickname] = userChannels
+	delete(n.users, oldKey)
+
+	user.info.Nickname = newNickname
+	n.users[newKey] = user
 
-	delete(n.usersChannels, oldNickName)
+	for _, channel := range user.channels {
+		if member, ok := channel.users[oldKey]; ok {
+			delete(channel.users, oldKey)
+			channel.users[newKey] = member
+		}
+		channels = append(channels, channel)
+	}
 
 	return channels
 }
 
+func (n *Network) deliverPrivMessage(sender, target string, msg ChannelMessage) {
+	if !n.IsChannel(target) {
+		query, opened := n.getOrAddQuery(sender)
+		if opened {
+			n.emitEvent(QueryOpenedEvent{
+				Query: query,
+			})
+		}
+		query.deliver(msg)
+		return
+	}
+
+	channel, ok := n.getChannel(target)
+	if !ok {
+		return
+	}
+	channel.msgs <- msg
+}
+
 func (n *Network) fetchMessage() (message, error) {
+	n.extendReadDeadline()
+
 	raw, truncated, err := n.conn.read()
 	if truncated {
 		return nil, errors.New("message was truncated due to its size")
@@ -351,6 +525,14 @@ func (n *Network) fetchMessage() (message, error) {
 	return decodeMessage(raw), nil
 }
 
+func (n *Network) getMessageTime(msg message) time.Time {
+	if !n.caps.isEnabled("server-time") {
+		return time.Time{}
+	}
+
+	return msg.getServerTime()
+}
+
 func (n *Network) GetHost() string {
 	return n.conn.getHost()
 }
@@ -366,12 +548,22 @@ func (n *Network) StartListener() {
 			for _, channel := range n.getChannels() {
 				channel.stopReceivingMsgs()
 			}
+			for _, query := range n.getQueries() {
+				query.stopReceivingMsgs()
+			}
+			for _, chat := range n.getDCCChats() {
+				chat.Close()
+			}
 
 			n.closeAndCleanup()
 
+			close(n.stopped)
 			close(n.msgs)
+			n.closeEvents()
 		}()
 
+		n.startKeepalive()
+
 		var (
 			msg message
 			err error
@@ -379,24 +571,38 @@ func (n *Network) StartListener() {
 		for {
 			msg, err = n.fetchMessage()
 			if err != nil {
-				if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				if errors.Is(err, os.ErrDeadlineExceeded) {
+					n.msgs <- NetworkMessage{
+						Content: "Ping timeout: no data received for " + time.Duration(n.config.Ping.Timeout).String(),
+					}
+				} else if err != io.EOF && !errors.Is(err, net.ErrClosed) {
 					log.Printf("Failed to read message from network: %v\n", err)
 				}
 				return
 			}
 
+			msgTime := n.getMessageTime(msg)
+
 			switch cmsg := msg.(type) {
 			case replyMessage:
+				n.trackServicesLogin(cmsg)
+				if n.handleNicknameReply(cmsg, msgTime) || n.handleAwayReply(cmsg, msgTime) || n.handleInviteReply(cmsg, msgTime) || n.handleBanReply(cmsg) || n.handleListReply(cmsg, msgTime) || n.handleWhoReply(cmsg, msgTime) || n.handleWhoisReply(cmsg, msgTime) {
+					break
+				}
 				switch cmsg.code {
 				case rpl_WELCOME:
 					n.registered.Store(true)
+					n.caps.finishNegotiation()
 					n.setNickname(cmsg.target)
+					n.emitEvent(RegisteredEvent{
+						Nickname: cmsg.target,
+					})
+					n.identifyWithServices()
 					fallthrough
 				case
 					rpl_YOURHOST,
 					rpl_CREATED,
 					rpl_MYINFO,
-					rpl_BOUNCE,
 					rpl_LUSERCLIENT,
 					rpl_LUSEROP,
 					rpl_LUSERUNKNOWN,
@@ -406,6 +612,7 @@ func (n *Network) StartListener() {
 					rpl_LUSERS,
 					rpl_GUSERS,
 					rpl_AWAY,
+					rpl_ENDOFWHO,
 					rpl_MOTDSTART,
 					rpl_MOTD,
 					rpl_DHOST,
@@ -413,67 +620,241 @@ func (n *Network) StartListener() {
 					err_NICKCOLLISION,
 					err_NOTREGISTERED,
 					err_ALREADYREGISTRED:
-					cmsg.content = strings.TrimPrefix(cmsg.content, n.getNickname())
-					cmsg.content = strings.TrimPrefix(cmsg.content, " :")
 					n.msgs <- NetworkMessage{
-						Content: cmsg.content,
+						Time:    msgTime,
+						Content: cmsg.getContent(),
+					}
+				case err_NOSUCHNICK:
+					nickname := cmsg.getParam(0)
+					n.msgs <- NetworkMessage{
+						Time:    msgTime,
+						Content: "No such nickname " + nickname,
 					}
 				case err_NOSUCHCHANNEL:
-					tag := strings.TrimPrefix(cmsg.content, " :")
+					tag := cmsg.getParam(0)
 					n.msgs <- NetworkMessage{
+						Time:    msgTime,
 						Content: "No such channel with name " + tag,
 					}
 				case err_NOTONCHANNEL:
-					tag := strings.TrimPrefix(cmsg.content, " :")
+					tag := cmsg.getParam(0)
 					n.msgs <- NetworkMessage{
+						Time:    msgTime,
 						Content: "You aren't on channel " + tag,
 					}
 				case err_ERRONEUSNICKNAME:
-					nickname := n.getNickname()
+					nickname := cmsg.getParam(0)
 					n.msgs <- NetworkMessage{
+						Time:    msgTime,
 						Content: "Nickname " + nickname + " is invalid",
 					}
 				case err_NICKNAMEINUSE:
-					nickname, _, _ := strings.Cut(cmsg.content, " :")
+					nickname := cmsg.getParam(0)
 					n.msgs <- NetworkMessage{
+						Time:    msgTime,
 						Content: nickname + " is already in use",
 					}
-				case rpl_TOPIC, err_INVITEONLYCHAN, err_BANNEDFROMCHAN, err_CANNOTSENDTOCHAN:
-					parts := strings.SplitN(cmsg.content, " :", 2)
-					tag := parts[0]
-					topic := parts[1]
+				case rpl_CHANNELMODEIS:
+					tag := cmsg.getParam(0)
+					var args []string
+					if len(cmsg.params) > 2 {
+						args = cmsg.params[2:]
+					}
+					changes := n.GetISupport().ParseModes(cmsg.getParam(1), args)
+					channel, ok := n.updateChannelModes(tag, changes, true)
+					if !ok {
+						n.msgs <- NetworkMessage{
+							Time:    msgTime,
+							Content: "Modes of " + tag + " are " + strings.Join(append([]string{cmsg.getParam(1)}, args...), " "),
+						}
+						break
+					}
+					content := "Channel has no modes set"
+					if modes := channel.GetModes(); modes != "" {
+						content = "Channel modes are " + modes
+					}
+					channel.msgs <- ChannelMessage{
+						Time:    msgTime,
+						Content: content,
+					}
+				case rpl_NOTOPIC:
+					tag := cmsg.getParam(0)
+					channel, ok := n.updateTopic(tag, func(topic *ChannelTopic) {
+						*topic = ChannelTopic{}
+					})
+					if !ok {
+						n.msgs <- NetworkMessage{
+							Time:    msgTime,
+							Content: tag + " has no topic",
+						}
+						break
+					}
+					channel.msgs <- ChannelMessage{
+						Time:    msgTime,
+						Content: "No topic is set",
+					}
+				case rpl_TOPIC:
+					tag := cmsg.getParam(0)
+					text := cmsg.getParam(1)
+					channel, ok := n.updateTopic(tag, func(topic *ChannelTopic) {
+						topic.Text = text
+					})
+					if !ok {
+						n.msgs <- NetworkMessage{
+							Time:    msgTime,
+							Content: "Topic of " + tag + ": " + text,
+						}
+						break
+					}
+					channel.msgs <- ChannelMessage{
+						Time:    msgTime,
+						Content: "Topic: " + text,
+					}
+				case rpl_TOPICWHOTIME:
+					tag := cmsg.getParam(0)
+					setter, _, _ := strings.Cut(cmsg.getParam(1), "!")
+					setTime := parseTopicTime(cmsg.getParam(2))
+					n.updateTopic(tag, func(topic *ChannelTopic) {
+						topic.Setter = setter
+						topic.Time = setTime
+					})
+				case err_CHANNELISFULL, err_INVITEONLYCHAN, err_BANNEDFROMCHAN, err_BADCHANNELKEY, err_TOOMANYCHANNELS:
+					tag := cmsg.getParam(0)
+					reason := cmsg.getParam(1)
+					channel, ok := n.getChannel(tag)
+					if !ok {
+						n.msgs <- NetworkMessage{
+							Time:    msgTime,
+							Content: tag + ": " + reason,
+						}
+						break
+					}
+					if !channel.joined.Load() {
+						n.failJoin(channel, reason, msgTime)
+						break
+					}
+					channel.msgs <- ChannelMessage{
+						Time:    msgTime,
+						Content: reason,
+					}
+				case err_CANNOTSENDTOCHAN, err_CHANOPRIVSNEEDED:
+					tag := cmsg.getParam(0)
+					reason := cmsg.getParam(1)
 					channel, ok := n.getChannel(tag)
 					if !ok {
 						break
 					}
 					channel.msgs <- ChannelMessage{
-						Content: topic,
+						Time:    msgTime,
+						Content: reason,
 					}
 				case rpl_NAMREPLY:
-					parts := strings.Split(cmsg.content, " :")
-					tag := strings.Split(parts[0], " ")[1]
-					nicknames := []string{}
-					for nickname := range strings.SplitSeq(parts[1], " ") {
-						nicknames = append(nicknames, strings.TrimLeft(nickname, "@+"))
-					}
-					n.addChannelUsers(nicknames, tag)
-				case rpl_ENDOFNAMES, rpl_ENDOFMOTD, rpl_TOPICWHOTIME:
+					tag := cmsg.getParam(1)
+					prefixSymbols := n.GetISupport().PrefixSymbols
+					members := []ChannelMember{}
+					for entry := range strings.FieldsSeq(cmsg.getParam(2)) {
+						members = append(members, parseNamesEntry(entry, prefixSymbols))
+					}
+					n.addChannelUsers(members, tag)
+				case err_UNKNOWNCOMMAND:
+					if cmsg.getParam(0) == "CAP" {
+						n.caps.finishNegotiation()
+						break
+					}
+					n.msgs <- NetworkMessage{
+						Time:    msgTime,
+						Content: cmsg.getContent(),
+					}
+				case
+					rpl_LOGGEDIN,
+					rpl_LOGGEDOUT,
+					err_NICKLOCKED,
+					rpl_SASLSUCCESS,
+					err_SASLFAIL,
+					err_SASLTOOLONG,
+					err_SASLABORTED,
+					err_SASLALREADY,
+					rpl_SASLMECHS:
+					if err := n.handleSASLReply(cmsg); err != nil {
+						log.Printf("Failed to authenticate: %v\n", err)
+						return
+					}
+				case rpl_ISUPPORT:
+					if len(cmsg.params) > 1 {
+						n.handleISupport(cmsg.params[:len(cmsg.params)-1])
+					}
+				case rpl_ENDOFNAMES, rpl_ENDOFMOTD, rpl_CREATIONTIME:
 				case err_RESTRICTED:
 					n.msgs <- NetworkMessage{
-						Content: cmsg.content,
+						Time:    msgTime,
+						Content: cmsg.getContent(),
 					}
 					return
 				default:
 					log.Printf("Unknown reply -> %s\n", cmsg.getUnparsed())
 				}
 			case quitMessage:
-				uorigin := cmsg.origin.(userOrigin)
+				uorigin, ok := cmsg.origin.(userOrigin)
+				if !ok {
+					break
+				}
 				nickname := uorigin.nickname
+				n.regainNickname(nickname)
 				for _, channel := range n.removeUser(nickname) {
 					channel.msgs <- ChannelMessage{
+						Time:    msgTime,
 						Content: nickname + " has quit",
 					}
 				}
+				if query, ok := n.getQuery(nickname); ok {
+					query.msgs <- ChannelMessage{
+						Time:    msgTime,
+						Content: nickname + " has quit",
+					}
+				}
+			case inviteMessage:
+				n.handleInvite(cmsg, msgTime)
+			case awayMessage:
+				if uorigin, ok := cmsg.origin.(userOrigin); ok {
+					n.handleAway(uorigin.nickname, cmsg.content)
+				}
+			case chghostMessage:
+				if uorigin, ok := cmsg.origin.(userOrigin); ok {
+					n.handleChghost(uorigin.nickname, cmsg.user, cmsg.host)
+				}
+			case accountMessage:
+				if uorigin, ok := cmsg.origin.(userOrigin); ok {
+					n.handleAccount(uorigin.nickname, cmsg.account)
+				}
+			case topicMessage:
+				setter := cmsg.getSender()
+				if uorigin, ok := cmsg.origin.(userOrigin); ok {
+					setter = uorigin.nickname
+				}
+				setTime := msgTime
+				if setTime.IsZero() {
+					setTime = time.Now()
+				}
+				channel, ok := n.updateTopic(cmsg.channelTag, func(topic *ChannelTopic) {
+					*topic = ChannelTopic{
+						Text:   cmsg.topic,
+						Setter: setter,
+						Time:   setTime,
+					}
+				})
+				if !ok {
+					break
+				}
+				var msgContent string
+				if cmsg.topic == "" {
+					msgContent = setter + " cleared the topic"
+				} else {
+					msgContent = setter + " changed the topic to: " + cmsg.topic
+				}
+				channel.msgs <- ChannelMessage{
+					Time:    msgTime,
+					Content: msgContent,
+				}
 			case kickMessage:
 				tag := cmsg.channelTag
 				nickname := cmsg.nickname
@@ -491,29 +872,56 @@ func (n *Network) StartListener() {
 					msgContent += ". Reason: " + cmsg.reason
 				}
 				channel.msgs <- ChannelMessage{
+					Time:    msgTime,
 					Content: msgContent,
 				}
 			case joinMessage:
-				uorigin := cmsg.origin.(userOrigin)
+				uorigin, ok := cmsg.origin.(userOrigin)
+				if !ok {
+					break
+				}
 				nickname := uorigin.nickname
-				tag := strings.TrimLeft(cmsg.channelTag, ":")
-				channel, ok := n.addChannelUsers([]string{nickname}, tag)
+				tag := cmsg.channelTag
+				channel, ok := n.addChannelUsers([]ChannelMember{{
+					Nickname: nickname,
+					User:     uorigin.user,
+					Host:     uorigin.host,
+				}}, tag)
 				if !ok {
 					break
 				}
+				n.updateUser(nickname, func(info *UserInfo) {
+					if cmsg.account != "" {
+						info.Account = cmsg.account
+					}
+					if cmsg.realname != "" {
+						info.Realname = cmsg.realname
+					}
+				})
 				var msgContent string
 				if n.hasNickname(nickname) {
+					channel.joined.Store(true)
+					if err := n.requestChannelModes(tag); err != nil {
+						log.Printf("Failed to request modes of %s: %v\n", tag, err)
+					}
+					if err := n.requestChannelUsers(tag); err != nil {
+						log.Printf("Failed to request users of %s: %v\n", tag, err)
+					}
 					msgContent = "You have joined " + tag
 				} else {
 					msgContent = nickname + " has joined " + tag
 				}
 				channel.msgs <- ChannelMessage{
+					Time:    msgTime,
 					Content: msgContent,
 				}
 			case partMessage:
-				uorigin := cmsg.origin.(userOrigin)
+				uorigin, ok := cmsg.origin.(userOrigin)
+				if !ok {
+					break
+				}
 				nickname := uorigin.nickname
-				tag := strings.TrimLeft(cmsg.channelTag, ":")
+				tag := cmsg.channelTag
 				channel, ok := n.removeChannelUser(nickname, tag)
 				if !ok {
 					break
@@ -522,23 +930,39 @@ func (n *Network) StartListener() {
 					break
 				}
 				channel.msgs <- ChannelMessage{
+					Time:    msgTime,
 					Content: nickname + " has left " + tag,
 				}
 			case nickMessage:
-				uorigin := cmsg.origin.(userOrigin)
+				uorigin, ok := cmsg.origin.(userOrigin)
+				if !ok {
+					break
+				}
 				oldNickName := uorigin.nickname
 				newNickname := cmsg.nickname
 				var msgContent string
 				if n.replaceNickname(oldNickName, newNickname) {
 					msgContent = fmt.Sprintf("You're now known as %s", newNickname)
 					n.msgs <- NetworkMessage{
+						Time:    msgTime,
 						Content: msgContent,
 					}
+					if n.isSameName(newNickname, n.GetPrimaryNickname()) {
+						n.stopRegain()
+					}
 				} else {
 					msgContent = fmt.Sprintf("%s changed his nickname to %s", oldNickName, newNickname)
+					n.regainNickname(oldNickName)
 				}
 				for _, channel := range n.replaceUser(oldNickName, newNickname) {
 					channel.msgs <- ChannelMessage{
+						Time:    msgTime,
+						Content: msgContent,
+					}
+				}
+				if query, ok := n.renameQuery(oldNickName, newNickname); ok {
+					query.msgs <- ChannelMessage{
+						Time:    msgTime,
 						Content: msgContent,
 					}
 				}
@@ -547,45 +971,81 @@ func (n *Network) StartListener() {
 				if !ok {
 					break
 				}
-				if !strings.HasPrefix(cmsg.target, "#") {
-					break
-				}
-				channel, ok := n.getChannel(cmsg.target)
-				if !ok {
+				if payload, ok := decodeCTCP(cmsg.content); ok {
+					if err := n.handleCTCPRequest(uorigin.nickname, cmsg.target, payload, msgTime); err != nil {
+						log.Printf("Failed to reply to CTCP: %v\n", err)
+						return
+					}
 					break
 				}
-				channel.msgs <- ChannelMessage{
+				n.deliverPrivMessage(uorigin.nickname, cmsg.target, ChannelMessage{
+					Time:    msgTime,
 					Sender:  uorigin.nickname,
 					Content: cmsg.content,
-				}
+				})
+			case pongMessage:
+				n.handlePong(cmsg.server)
 			case pingMessage:
-				sorigin := cmsg.origin.(serverOrigin)
 				pongMsg := pongMessage{
-					server: sorigin.servername,
+					server: cmsg.token,
 				}
 				if err := n.conn.write(pongMsg.encode()); err != nil {
 					log.Printf("Failed to send pong: %v\n", err)
 					return
 				}
 			case noticeMessage:
+				if uorigin, ok := cmsg.origin.(userOrigin); ok {
+					if payload, ok := decodeCTCP(cmsg.content); ok {
+						n.handleCTCPReply(uorigin.nickname, payload, msgTime)
+						break
+					}
+					if n.IsService(uorigin.nickname) {
+						n.handleServiceNotice(uorigin.nickname, cmsg.content, msgTime)
+						break
+					}
+				}
 				n.msgs <- NetworkMessage{
+					Time:    msgTime,
 					Content: cmsg.content,
 				}
 			case errorMessage:
-				_, after, found := strings.Cut(cmsg.content, " :")
-				if found {
-					cmsg.content = after
-				}
 				n.msgs <- NetworkMessage{
+					Time:    msgTime,
 					Content: "ERROR " + cmsg.content,
 				}
 				return
 			case modeMessage:
-				if strings.HasPrefix(cmsg.target, "#") {
+				if n.IsChannel(cmsg.target) {
+					changes := n.GetISupport().ParseModes(cmsg.modes, cmsg.args)
+					channel, ok := n.updateChannelModes(cmsg.target, changes, false)
+					if !ok {
+						break
+					}
+					setter := cmsg.getSender()
+					if uorigin, ok := cmsg.origin.(userOrigin); ok {
+						setter = uorigin.nickname
+					}
+					for _, change := range changes {
+						channel.msgs <- ChannelMessage{
+							Time:    msgTime,
+							Content: setter + " sets " + change.String(),
+						}
+					}
 					break
 				}
 				n.msgs <- NetworkMessage{
-					Content: "Your modes are " + cmsg.modes,
+					Time:    msgTime,
+					Content: "Your modes are " + strings.Join(append([]string{cmsg.modes}, cmsg.args...), " "),
+				}
+			case capMessage:
+				if err := n.handleCap(cmsg); err != nil {
+					log.Printf("Failed to negotiate capabilities: %v\n", err)
+					return
+				}
+			case authenticateMessage:
+				if err := n.handleAuthenticate(cmsg); err != nil {
+					log.Printf("Failed to authenticate: %v\n", err)
+					return
 				}
 			case unknownMessage:
 				log.Printf("Unknown message -> %s\n", msg.getUnparsed())
@@ -595,6 +1055,17 @@ func (n *Network) StartListener() {
 }
 
 func (n *Network) Register(nickname, realname string) error {
+	n.setPrimaryNickname(nickname)
+	n.caps.startNegotiation()
+
+	capMsg := capMessage{
+		subcommand: "LS",
+		version:    capVersion,
+	}
+	if err := n.conn.write(capMsg.encode()); err != nil {
+		return err
+	}
+
 	nickMsg := nickMessage{
 		nickname: nickname,
 	}
@@ -630,21 +1101,59 @@ func (n *Network) ReceiveMessage() (NetworkMessage, bool) {
 	return msg, ok
 }
 
-func (n *Network) JoinChannel(tag string) (*NetworkChannel, error) {
+func (n *Network) emitEvent(event Event) {
+	n.emx.Lock()
+	defer n.emx.Unlock()
+
+	if n.eventsClosed {
+		return
+	}
+
+	select {
+	case n.events <- event:
+	case <-n.eventsDone:
+	}
+}
+
+func (n *Network) closeEvents() {
+	n.eventsOnce.Do(func() {
+		close(n.eventsDone)
+	})
+
+	n.emx.Lock()
+	defer n.emx.Unlock()
+
+	if n.eventsClosed {
+		return
+	}
+	n.eventsClosed = true
+
+	close(n.events)
+}
+
+func (n *Network) ReceiveEvent() (Event, bool) {
+	event, ok := <-n.events
+	return event, ok
+}
+
+func (n *Network) JoinChannel(tag, key string) (*NetworkChannel, error) {
 	if _, ok := n.getChannel(tag); ok {
 		return nil, fmt.Errorf("already connected to %s", tag)
 	}
 
 	channel := &NetworkChannel{
 		tag:        tag,
+		key:        key,
 		noMoreMsgs: make(chan struct{}, 1),
 		msgs:       make(chan ChannelMessage, messagesBufSize),
 		network:    n,
-		users:      map[string]struct{}{},
+		users:      map[string]*channelMember{},
+		modes:      map[byte]string{},
 	}
 
 	joinMsg := joinMessage{
 		channelTag: tag,
+		key:        key,
 	}
 	if err := n.conn.write(joinMsg.encode()); err != nil {
 		return nil, err
@@ -660,6 +1169,9 @@ func (n *Network) ChangeNickname(newNickname string) error {
 		return nil
 	}
 
+	n.stopRegain()
+	n.setPrimaryNickname(newNickname)
+
 	nickMsg := nickMessage{
 		nickname: newNickname,
 	}
@@ -669,6 +1181,7 @@ func (n *Network) ChangeNickname(newNickname string) error {
 func (n *Network) Quit(message string) error {
 	if !n.listenerStarted {
 		close(n.msgs)
+		n.closeEvents()
 	}
 
 	quitMsg := quitMessage{
@@ -680,14 +1193,37 @@ func (n *Network) Quit(message string) error {
 
 	n.closeAndCleanup()
 
+	if dropped := n.queue.getDropped(); dropped > 0 {
+		return fmt.Errorf("%d queued messages weren't sent", dropped)
+	}
+
 	return nil
 }
 
-func NewNetwork(conn Connection) *Network {
-	return &Network{
-		conn:          conn,
-		channels:      map[string]*NetworkChannel{},
-		usersChannels: map[string]map[string]*NetworkChannel{},
-		msgs:          make(chan NetworkMessage, messagesBufSize),
+func NewNetwork(conn Connection, config config.Network) *Network {
+	n := &Network{
+		caps: capabilities{
+			available: map[string]string{},
+			enabled:   map[string]struct{}{},
+		},
+		config:       config,
+		isupport:     DefaultISupport(),
+		whois:        map[string]*WhoisResult{},
+		pendingWho:   map[string]struct{}{},
+		dccTransport: tcpTransport{},
+		transfers: dccTransfers{
+			byID: map[int]*DCCTransfer{},
+		},
+		channels:   map[string]*NetworkChannel{},
+		users:      map[string]*networkUser{},
+		queries:    map[string]*NetworkQuery{},
+		msgs:       make(chan NetworkMessage, messagesBufSize),
+		events:     make(chan Event, messagesBufSize),
+		eventsDone: make(chan struct{}),
+		stopped:    make(chan struct{}),
 	}
+	n.queue = newSendQueue(conn, config.Flood, n.onSendFailure)
+	n.conn = n.queue
+
+	return n
 }
@@ -0,0 +1,160 @@
+package irc
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxNicknameSuffixes = 9
+
+func (n *Network) setPrimaryNickname(nickname string) {
+	n.nmx.Lock()
+	defer n.nmx.Unlock()
+
+	n.primaryNickname = nickname
+	n.nicknameAttempts = 0
+}
+
+func (n *Network) GetPrimaryNickname() string {
+	n.nmx.Lock()
+	defer n.nmx.Unlock()
+
+	return n.primaryNickname
+}
+
+func (n *Network) nextFallbackNickname() (string, bool) {
+	n.nmx.Lock()
+	defer n.nmx.Unlock()
+
+	attempt := n.nicknameAttempts
+	n.nicknameAttempts++
+
+	alternates := n.config.Nick.Alternates
+	if attempt < len(alternates) {
+		return alternates[attempt], true
+	}
+
+	attempt -= len(alternates)
+	if attempt >= maxNicknameSuffixes {
+		return "", false
+	}
+
+	var suffix string
+	if attempt < 2 {
+		suffix = strings.Repeat("_", attempt+1)
+	} else {
+		suffix = strconv.Itoa(attempt - 1)
+	}
+
+	nickname := n.primaryNickname
+	if nickLen := n.GetISupport().NickLen; nickLen > 0 && len(nickname)+len(suffix) > nickLen {
+		nickname = nickname[:max(0, nickLen-len(suffix))]
+	}
+
+	return nickname + suffix, true
+}
+
+func (n *Network) tryFallbackNickname(rejected, reason string, msgTime time.Time) {
+	nickname, ok := n.nextFallbackNickname()
+	if !ok {
+		n.msgs <- NetworkMessage{
+			Time:    msgTime,
+			Content: rejected + " " + reason + ". Pick another one with /nick",
+		}
+		return
+	}
+
+	n.msgs <- NetworkMessage{
+		Time:    msgTime,
+		Content: rejected + " " + reason + ", trying " + nickname,
+	}
+
+	nickMsg := nickMessage{
+		nickname: nickname,
+	}
+	if err := n.conn.write(nickMsg.encode()); err != nil {
+		log.Printf("Failed to send fallback nickname: %v\n", err)
+	}
+}
+
+func (n *Network) shouldRegain() bool {
+	if !n.config.Nick.Regain || !n.IsRegistered() {
+		return false
+	}
+
+	return !n.hasNickname(n.GetPrimaryNickname())
+}
+
+func (n *Network) startRegain() {
+	if !n.shouldRegain() || !n.GetISupport().Monitor {
+		return
+	}
+
+	monitorMsg := monitorMessage{
+		adding:  true,
+		targets: []string{n.GetPrimaryNickname()},
+	}
+	if err := n.conn.write(monitorMsg.encode()); err != nil {
+		log.Printf("Failed to monitor primary nickname: %v\n", err)
+	}
+}
+
+func (n *Network) stopRegain() {
+	if !n.config.Nick.Regain || !n.GetISupport().Monitor {
+		return
+	}
+
+	monitorMsg := monitorMessage{
+		targets: []string{n.GetPrimaryNickname()},
+	}
+	if err := n.conn.write(monitorMsg.encode()); err != nil {
+		log.Printf("Failed to stop monitoring primary nickname: %v\n", err)
+	}
+}
+
+func (n *Network) regainNickname(freed string) {
+	if !n.shouldRegain() {
+		return
+	}
+
+	primary := n.GetPrimaryNickname()
+	if !n.isSameName(freed, primary) {
+		return
+	}
+
+	nickMsg := nickMessage{
+		nickname: primary,
+	}
+	if err := n.conn.write(nickMsg.encode()); err != nil {
+		log.Printf("Failed to regain nickname: %v\n", err)
+	}
+}
+
+func (n *Network) handleNicknameReply(msg replyMessage, msgTime time.Time) bool {
+	switch msg.code {
+	case err_NICKNAMEINUSE, err_ERRONEUSNICKNAME:
+		if n.IsRegistered() {
+			return false
+		}
+		reason := "is already in use"
+		if msg.code == err_ERRONEUSNICKNAME {
+			reason = "is invalid"
+		}
+		n.tryFallbackNickname(msg.getParam(0), reason, msgTime)
+	case rpl_ENDOFMOTD, err_NOMOTD:
+		n.startRegain()
+		return false
+	case rpl_MONOFFLINE:
+		for target := range strings.SplitSeq(msg.getParam(0), ",") {
+			nickname, _, _ := strings.Cut(target, "!")
+			n.regainNickname(nickname)
+		}
+	case rpl_MONONLINE, rpl_MONLIST, rpl_ENDOFMONLIST, err_MONLISTFULL:
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,243 @@
+package irc
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	errEmptyMessage   = errors.New("empty message")
+	errMissingCommand = errors.New("message without command")
+)
+
+var tagValueEscapes = []struct {
+	escaped   string
+	unescaped byte
+}{
+	{`\:`, ';'},
+	{`\s`, ' '},
+	{`\\`, '\\'},
+	{`\r`, '\r'},
+	{`\n`, '\n'},
+}
+
+func unescapeTagValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	b.Grow(len(value))
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		if i+1 == len(value) {
+			break
+		}
+
+		i++
+		escaped := false
+		for _, escape := range tagValueEscapes {
+			if escape.escaped[1] == value[i] {
+				b.WriteByte(escape.unescaped)
+				escaped = true
+				break
+			}
+		}
+		if !escaped {
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
+
+func escapeTagValue(value string) string {
+	var b strings.Builder
+	b.Grow(len(value))
+
+	for i := 0; i < len(value); i++ {
+		escaped := false
+		for _, escape := range tagValueEscapes {
+			if escape.unescaped == value[i] {
+				b.WriteString(escape.escaped)
+				escaped = true
+				break
+			}
+		}
+		if !escaped {
+			b.WriteByte(value[i])
+		}
+	}
+
+	return b.String()
+}
+
+func parseTags(raw string) map[string]string {
+	tags := map[string]string{}
+
+	for tag := range strings.SplitSeq(raw, ";") {
+		if tag == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(tag, "=")
+		tags[key] = unescapeTagValue(value)
+	}
+
+	return tags
+}
+
+type Source struct {
+	Name, User, Host string
+}
+
+func parseSource(raw string) Source {
+	var source Source
+
+	rest, host, hasHost := strings.Cut(raw, "@")
+	if hasHost {
+		source.Host = host
+	}
+	name, user, hasUser := strings.Cut(rest, "!")
+	if hasUser {
+		source.User = user
+	}
+	source.Name = name
+
+	return source
+}
+
+func (s Source) IsServer() bool {
+	return s.User == "" && s.Host == "" && strings.ContainsAny(s.Name, ".:")
+}
+
+func (s Source) String() string {
+	source := s.Name
+	if s.User != "" {
+		source += "!" + s.User
+	}
+	if s.Host != "" {
+		source += "@" + s.Host
+	}
+
+	return source
+}
+
+type Message struct {
+	Tags    map[string]string
+	Source  *Source
+	Command string
+	Params  []string
+}
+
+func (m Message) GetTag(key string) (string, bool) {
+	value, ok := m.Tags[key]
+	return value, ok
+}
+
+func (m Message) GetParam(index int) string {
+	if index < 0 || index >= len(m.Params) {
+		return ""
+	}
+
+	return m.Params[index]
+}
+
+func (m Message) GetTrailing() string {
+	return m.GetParam(len(m.Params) - 1)
+}
+
+func (m Message) Encode() []byte {
+	var b strings.Builder
+
+	if len(m.Tags) > 0 {
+		b.WriteByte('@')
+		for i, key := range slices.Sorted(maps.Keys(m.Tags)) {
+			if i > 0 {
+				b.WriteByte(';')
+			}
+			value := m.Tags[key]
+			b.WriteString(key)
+			if value != "" {
+				b.WriteByte('=')
+				b.WriteString(escapeTagValue(value))
+			}
+		}
+		b.WriteByte(' ')
+	}
+
+	if m.Source != nil {
+		b.WriteByte(':')
+		b.WriteString(m.Source.String())
+		b.WriteByte(' ')
+	}
+
+	b.WriteString(m.Command)
+
+	for i, param := range m.Params {
+		b.WriteByte(' ')
+		if i == len(m.Params)-1 &&
+			(param == "" || param[0] == ':' || strings.Contains(param, " ")) {
+			b.WriteByte(':')
+		}
+		b.WriteString(param)
+	}
+
+	b.WriteString("\r\n")
+
+	return []byte(b.String())
+}
+
+func ParseMessage(raw string) (Message, error) {
+	var msg Message
+
+	raw = strings.TrimRight(raw, "\r\n")
+	raw = strings.TrimLeft(raw, " ")
+	if raw == "" {
+		return msg, errEmptyMessage
+	}
+
+	if raw[0] == '@' {
+		var rawTags string
+		rawTags, raw, _ = strings.Cut(raw[1:], " ")
+		msg.Tags = parseTags(rawTags)
+		raw = strings.TrimLeft(raw, " ")
+	}
+
+	if raw != "" && raw[0] == ':' {
+		var rawSource string
+		rawSource, raw, _ = strings.Cut(raw[1:], " ")
+		source := parseSource(rawSource)
+		msg.Source = &source
+		raw = strings.TrimLeft(raw, " ")
+	}
+
+	msg.Command, raw, _ = strings.Cut(raw, " ")
+	if msg.Command == "" {
+		return msg, errMissingCommand
+	}
+	msg.Command = strings.ToUpper(msg.Command)
+
+	msg.Params = []string{}
+	for raw != "" {
+		raw = strings.TrimLeft(raw, " ")
+		if raw == "" {
+			break
+		}
+		if raw[0] == ':' {
+			msg.Params = append(msg.Params, raw[1:])
+			break
+		}
+		var param string
+		param, raw, _ = strings.Cut(raw, " ")
+		msg.Params = append(msg.Params, param)
+	}
+
+	return msg, nil
+}
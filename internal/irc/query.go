@@ -0,0 +1,174 @@
+package irc
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type NetworkQuery struct {
+	tmx        sync.Mutex
+	tag        string
+	closed     atomic.Bool
+	noMoreMsgs chan struct{}
+	done       chan struct{}
+	msgs       chan ChannelMessage
+	network    *Network
+}
+
+func (nq *NetworkQuery) signalNoMoreMsgs() {
+	select {
+	case nq.noMoreMsgs <- struct{}{}:
+	default:
+	}
+}
+
+func (nq *NetworkQuery) stopReceivingMsgs() {
+	if !nq.closed.CompareAndSwap(false, true) {
+		return
+	}
+	close(nq.done)
+
+	nq.signalNoMoreMsgs()
+}
+
+func (nq *NetworkQuery) deliver(msg ChannelMessage) {
+	if nq.closed.Load() {
+		return
+	}
+
+	select {
+	case nq.msgs <- msg:
+	case <-nq.done:
+	}
+}
+
+func (nq *NetworkQuery) setTag(tag string) {
+	nq.tmx.Lock()
+	defer nq.tmx.Unlock()
+
+	nq.tag = tag
+}
+
+func (nq *NetworkQuery) GetTag() string {
+	nq.tmx.Lock()
+	defer nq.tmx.Unlock()
+
+	return nq.tag
+}
+
+func (nq *NetworkQuery) SendMessage(content string) error {
+	privMsg := privMessage{
+		target:  nq.GetTag(),
+		content: content,
+	}
+	return nq.network.conn.write(privMsg.encode())
+}
+
+func (nq *NetworkQuery) SendAction(content string) error {
+	return nq.network.sendAction(nq.GetTag(), content)
+}
+
+func (nq *NetworkQuery) ReceiveMessage() (ChannelMessage, bool) {
+	if nq.closed.Load() {
+		return ChannelMessage{}, false
+	}
+
+	select {
+	case <-nq.noMoreMsgs:
+		return ChannelMessage{}, false
+	case msg := <-nq.msgs:
+		return msg, true
+	}
+}
+
+func (nq *NetworkQuery) Close() {
+	if !nq.closed.CompareAndSwap(false, true) {
+		return
+	}
+	close(nq.done)
+
+	nq.signalNoMoreMsgs()
+
+	nq.network.removeQuery(nq.GetTag())
+}
+
+func newNetworkQuery(network *Network, nickname string) *NetworkQuery {
+	return &NetworkQuery{
+		tag:        nickname,
+		noMoreMsgs: make(chan struct{}, 1),
+		done:       make(chan struct{}),
+		msgs:       make(chan ChannelMessage, messagesBufSize),
+		network:    network,
+	}
+}
+
+func (n *Network) getOrAddQuery(nickname string) (*NetworkQuery, bool) {
+	key := n.Fold(nickname)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	if query, ok := n.queries[key]; ok {
+		return query, false
+	}
+
+	query := newNetworkQuery(n, nickname)
+	n.queries[key] = query
+
+	return query, true
+}
+
+func (n *Network) getQuery(nickname string) (*NetworkQuery, bool) {
+	nickname = n.Fold(nickname)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	query, ok := n.queries[nickname]
+	return query, ok
+}
+
+func (n *Network) getQueries() []*NetworkQuery {
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	queries := []*NetworkQuery{}
+	for _, query := range n.queries {
+		queries = append(queries, query)
+	}
+
+	return queries
+}
+
+func (n *Network) removeQuery(nickname string) {
+	nickname = n.Fold(nickname)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	delete(n.queries, nickname)
+}
+
+func (n *Network) renameQuery(oldNickname, newNickname string) (*NetworkQuery, bool) {
+	oldKey := n.Fold(oldNickname)
+	newKey := n.Fold(newNickname)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	query, ok := n.queries[oldKey]
+	if !ok {
+		return nil, false
+	}
+
+	delete(n.queries, oldKey)
+	n.queries[newKey] = query
+	query.setTag(newNickname)
+
+	return query, true
+}
+
+func (n *Network) OpenQuery(nickname string) *NetworkQuery {
+	query, _ := n.getOrAddQuery(nickname)
+	return query
+}
@@ -0,0 +1,143 @@
+package irc
+
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+)
+
+const (
+	saslChunkSize         = 400
+	saslPlainMechanism    = "PLAIN"
+	saslExternalMechanism = "EXTERNAL"
+)
+
+func (n *Network) saslMechanism() string {
+	sasl := n.config.SASL
+	if sasl.Mechanism != "" {
+		return strings.ToUpper(sasl.Mechanism)
+	}
+	if n.config.ClientCert.IsSet() && sasl.Password == "" {
+		return saslExternalMechanism
+	}
+
+	return saslPlainMechanism
+}
+
+func (n *Network) saslEnabled() bool {
+	sasl := n.config.SASL
+
+	switch n.saslMechanism() {
+	case saslPlainMechanism:
+		return sasl.Account != "" && sasl.Password != ""
+	case saslExternalMechanism:
+		return n.config.ClientCert.IsSet()
+	}
+
+	return false
+}
+
+func (n *Network) saslPayload() []byte {
+	sasl := n.config.SASL
+
+	if n.saslMechanism() == saslExternalMechanism {
+		return nil
+	}
+
+	return []byte(sasl.Account + "\x00" + sasl.Account + "\x00" + sasl.Password)
+}
+
+func (n *Network) failRegistration(reason string) error {
+	n.msgs <- NetworkMessage{
+		Content: reason,
+	}
+
+	return errors.New(reason)
+}
+
+func (n *Network) checkSASLSupport() error {
+	if !n.saslEnabled() {
+		return nil
+	}
+
+	mechanisms, ok := n.caps.getValue("sasl")
+	if !ok {
+		return n.failRegistration("Server doesn't support SASL authentication")
+	}
+
+	mechanism := n.saslMechanism()
+	if mechanisms != "" && !slices.Contains(strings.Split(mechanisms, ","), mechanism) {
+		return n.failRegistration("Server doesn't support SASL mechanism " + mechanism)
+	}
+
+	return nil
+}
+
+func (n *Network) startSASL() error {
+	n.caps.hold()
+
+	authMsg := authenticateMessage{
+		payload: n.saslMechanism(),
+	}
+	return n.conn.write(authMsg.encode())
+}
+
+func (n *Network) handleAuthenticate(msg authenticateMessage) error {
+	if msg.payload != "+" {
+		return nil
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(n.saslPayload())
+	for len(encoded) >= saslChunkSize {
+		authMsg := authenticateMessage{
+			payload: encoded[:saslChunkSize],
+		}
+		if err := n.conn.write(authMsg.encode()); err != nil {
+			return err
+		}
+		encoded = encoded[saslChunkSize:]
+	}
+
+	if encoded == "" {
+		encoded = "+"
+	}
+	authMsg := authenticateMessage{
+		payload: encoded,
+	}
+	return n.conn.write(authMsg.encode())
+}
+
+func (n *Network) handleSASLReply(msg replyMessage) error {
+	switch msg.code {
+	case rpl_LOGGEDIN, rpl_LOGGEDOUT, rpl_SASLMECHS:
+		n.msgs <- NetworkMessage{
+			Content: msg.getParam(len(msg.params) - 1),
+		}
+	case rpl_SASLSUCCESS:
+		n.msgs <- NetworkMessage{
+			Content: "SASL authentication succeeded",
+		}
+		if n.caps.answerReceived() {
+			return n.endCapNegotiation()
+		}
+	case err_SASLALREADY:
+		n.msgs <- NetworkMessage{
+			Content: msg.getParam(len(msg.params) - 1),
+		}
+		if n.caps.answerReceived() {
+			return n.endCapNegotiation()
+		}
+	case err_NICKLOCKED, err_SASLFAIL, err_SASLTOOLONG, err_SASLABORTED:
+		reason := "SASL authentication failed: " + msg.getParam(len(msg.params)-1)
+		if n.IsRegistered() {
+			n.msgs <- NetworkMessage{
+				Content: reason,
+			}
+			break
+		}
+		return n.failRegistration(reason)
+	}
+
+	return nil
+}
@@ -0,0 +1,228 @@
+package irc
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/franciscosbf/irc-client/internal/config"
+)
+
+const sendQueueCloseTimeout = time.Second
+
+var errSendQueueClosed = errors.New("connection closed")
+
+type sendQueue struct {
+	Connection
+
+	burst    int
+	interval time.Duration
+	onFail   func(err error, dropped int)
+
+	mx       sync.Mutex
+	priority [][]byte
+	pending  [][]byte
+	tokens   int
+	refilled time.Time
+	closing  bool
+	dropped  int
+	err      error
+
+	wakeup chan struct{}
+}
+
+func commandOf(b []byte) string {
+	command, _, _ := bytes.Cut(bytes.TrimRight(b, "\r\n"), []byte(" "))
+	return string(command)
+}
+
+func isPrioritized(b []byte) bool {
+	switch commandOf(b) {
+	case "PONG":
+		return true
+	case "PING":
+		return bytes.HasPrefix(b, []byte("PING "+lagTokenPrefix))
+	}
+
+	return false
+}
+
+func (q *sendQueue) wake() {
+	select {
+	case q.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (q *sendQueue) refill(now time.Time) {
+	if q.interval <= 0 {
+		q.tokens = q.burst
+		return
+	}
+
+	if refills := int(now.Sub(q.refilled) / q.interval); refills > 0 {
+		q.tokens = min(q.tokens+refills, q.burst)
+		q.refilled = q.refilled.Add(time.Duration(refills) * q.interval)
+	}
+	if q.tokens == q.burst {
+		q.refilled = now
+	}
+}
+
+func (q *sendQueue) next() ([]byte, time.Duration, bool) {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	now := time.Now()
+	q.refill(now)
+
+	if len(q.priority) > 0 {
+		b := q.priority[0]
+		q.priority = q.priority[1:]
+		q.tokens = max(q.tokens-1, 0)
+		return b, 0, true
+	}
+	if q.closing {
+		return nil, 0, false
+	}
+	if len(q.pending) == 0 {
+		return nil, -1, true
+	}
+	if q.tokens == 0 {
+		return nil, q.refilled.Add(q.interval).Sub(now), true
+	}
+
+	b := q.pending[0]
+	q.pending = q.pending[1:]
+	q.tokens--
+	return b, 0, true
+}
+
+func (q *sendQueue) dropPending() {
+	q.dropped += len(q.pending)
+	q.pending = nil
+	q.closing = true
+}
+
+func (q *sendQueue) fail(err error) {
+	q.mx.Lock()
+	if q.err != nil {
+		q.mx.Unlock()
+		return
+	}
+	q.err = err
+	dropped := 1 + len(q.priority) + len(q.pending)
+	q.priority = nil
+	q.pending = nil
+	q.mx.Unlock()
+
+	q.Connection.close()
+
+	if q.onFail != nil {
+		q.onFail(err, dropped)
+	}
+}
+
+func (q *sendQueue) run() {
+	for {
+		b, wait, ok := q.next()
+		if !ok {
+			q.Connection.close()
+			return
+		}
+
+		if b != nil {
+			if err := q.Connection.write(b); err != nil {
+				q.fail(err)
+				return
+			}
+			continue
+		}
+
+		if wait < 0 {
+			<-q.wakeup
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-q.wakeup:
+			timer.Stop()
+		}
+	}
+}
+
+func (q *sendQueue) write(b []byte) error {
+	q.mx.Lock()
+	defer q.wake()
+	defer q.mx.Unlock()
+
+	if q.err != nil {
+		return q.err
+	}
+	if q.closing {
+		return errSendQueueClosed
+	}
+
+	switch {
+	case commandOf(b) == "QUIT":
+		q.dropPending()
+		q.priority = append(q.priority, b)
+	case isPrioritized(b):
+		q.priority = append(q.priority, b)
+	default:
+		q.pending = append(q.pending, b)
+	}
+
+	return nil
+}
+
+func (q *sendQueue) getQueued() int {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	return len(q.priority) + len(q.pending)
+}
+
+func (q *sendQueue) getDropped() int {
+	q.mx.Lock()
+	defer q.mx.Unlock()
+
+	return q.dropped
+}
+
+func (q *sendQueue) close() {
+	q.mx.Lock()
+	q.dropPending()
+	q.mx.Unlock()
+	q.wake()
+
+	time.AfterFunc(sendQueueCloseTimeout, q.Connection.close)
+}
+
+func newSendQueue(conn Connection, flood config.Flood, onFail func(err error, dropped int)) *sendQueue {
+	q := &sendQueue{
+		Connection: conn,
+		burst:      max(flood.Burst, 1),
+		interval:   time.Duration(flood.Interval),
+		onFail:     onFail,
+		tokens:     max(flood.Burst, 1),
+		refilled:   time.Now(),
+		wakeup:     make(chan struct{}, 1),
+	}
+	go q.run()
+
+	return q
+}
+
+func (n *Network) onSendFailure(err error, dropped int) {
+	n.emitEvent(SendFailedEvent{
+		Err:     err,
+		Dropped: dropped,
+	})
+}
+
+func (n *Network) GetQueuedCount() int {
+	return n.queue.getQueued()
+}
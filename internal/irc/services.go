@@ -0,0 +1,136 @@
+package irc
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+const servicesIdentifyTimeout = 15 * time.Second
+
+var identifiedConfirmations = []string{"now identified", "now recognized", "now logged in"}
+
+var maskedServiceCommands = []string{"IDENTIFY", "REGISTER", "GHOST", "RECOVER", "REGAIN", "RELEASE"}
+
+func (n *Network) stopIdentifyTimer() {
+	n.smx.Lock()
+	defer n.smx.Unlock()
+
+	if n.identifyTimer != nil {
+		n.identifyTimer.Stop()
+		n.identifyTimer = nil
+	}
+}
+
+func (n *Network) markReady() {
+	n.stopIdentifyTimer()
+
+	if !n.ready.CompareAndSwap(false, true) {
+		return
+	}
+
+	n.emitEvent(ServicesReadyEvent{
+		Identified: n.loggedIn.Load(),
+	})
+}
+
+func (n *Network) markIdentified() {
+	if n.identifying.CompareAndSwap(true, false) {
+		n.markReady()
+	}
+}
+
+func (n *Network) identifyWithServices() {
+	services := n.config.Services
+	if services.Password == "" || n.loggedIn.Load() {
+		n.markReady()
+		return
+	}
+
+	n.identifying.Store(true)
+	n.smx.Lock()
+	n.identifyTimer = time.AfterFunc(servicesIdentifyTimeout, func() {
+		n.identifying.Store(false)
+		n.markReady()
+	})
+	n.smx.Unlock()
+
+	content := "IDENTIFY " + services.Password
+	if services.Account != "" {
+		content = "IDENTIFY " + services.Account + " " + services.Password
+	}
+	if err := n.sendToService(services.NickServ, content); err != nil {
+		log.Printf("Failed to identify with %s: %v\n", services.NickServ, err)
+		n.markReady()
+	}
+}
+
+func (n *Network) IsService(nickname string) bool {
+	services := n.config.Services
+
+	return n.isSameName(nickname, services.NickServ) || n.isSameName(nickname, services.ChanServ)
+}
+
+func (n *Network) handleServiceNotice(service, content string, msgTime time.Time) {
+	if n.identifying.Load() && n.isSameName(service, n.config.Services.NickServ) {
+		lowered := strings.ToLower(content)
+		for _, confirmation := range identifiedConfirmations {
+			if strings.Contains(lowered, confirmation) {
+				n.loggedIn.Store(true)
+				n.markIdentified()
+				break
+			}
+		}
+	}
+
+	n.emitEvent(ServiceNoticeEvent{
+		Time:    msgTime,
+		Service: service,
+		Content: content,
+	})
+}
+
+func (n *Network) trackServicesLogin(msg replyMessage) {
+	switch msg.code {
+	case rpl_LOGGEDIN:
+		n.loggedIn.Store(true)
+		n.markIdentified()
+	case rpl_LOGGEDOUT:
+		n.loggedIn.Store(false)
+	}
+}
+
+func (n *Network) sendToService(service, content string) error {
+	privMsg := privMessage{
+		target:  service,
+		content: content,
+	}
+	return n.conn.write(privMsg.encode())
+}
+
+func MaskServiceCommand(content string) string {
+	command, args, _ := strings.Cut(content, " ")
+	for _, masked := range maskedServiceCommands {
+		if strings.EqualFold(command, masked) && args != "" {
+			return command + " ****"
+		}
+	}
+
+	return content
+}
+
+func (n *Network) NickServ(content string) error {
+	return n.sendToService(n.config.Services.NickServ, content)
+}
+
+func (n *Network) ChanServ(content string) error {
+	return n.sendToService(n.config.Services.ChanServ, content)
+}
+
+func (n *Network) GetNickServ() string {
+	return n.config.Services.NickServ
+}
+
+func (n *Network) GetChanServ() string {
+	return n.config.Services.ChanServ
+}
@@ -0,0 +1,59 @@
+package irc
+
+import (
+	"strconv"
+	"time"
+)
+
+type ChannelTopic struct {
+	Text   string
+	Setter string
+	Time   time.Time
+}
+
+func (nc *NetworkChannel) GetTopic() ChannelTopic {
+	nc.network.cmx.Lock()
+	defer nc.network.cmx.Unlock()
+
+	return nc.topic
+}
+
+func (n *Network) updateTopic(tag string, update func(topic *ChannelTopic)) (*NetworkChannel, bool) {
+	tag = n.Fold(tag)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	channel, ok := n.channels[tag]
+	if !ok {
+		return nil, false
+	}
+	update(&channel.topic)
+
+	return channel, true
+}
+
+func parseTopicTime(raw string) time.Time {
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
+
+func (n *Network) RequestTopic(tag string) error {
+	topicMsg := topicMessage{
+		channelTag: tag,
+	}
+	return n.conn.write(topicMsg.encode())
+}
+
+func (n *Network) SetTopic(tag, topic string) error {
+	topicMsg := topicMessage{
+		channelTag: tag,
+		topic:      topic,
+		set:        true,
+	}
+	return n.conn.write(topicMsg.encode())
+}
@@ -0,0 +1,238 @@
+package irc
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	whoxFields = "%tcuhnfar"
+	whoxToken  = "152"
+)
+
+type UserInfo struct {
+	Nickname    string
+	Username    string
+	Host        string
+	Realname    string
+	Account     string
+	Away        bool
+	AwayMessage string
+}
+
+func (ui UserInfo) GetHostmask() string {
+	return Source{Name: ui.Nickname, User: ui.Username, Host: ui.Host}.String()
+}
+
+func (ui *UserInfo) merge(other UserInfo) {
+	ui.Nickname = other.Nickname
+	if other.Username != "" {
+		ui.Username = other.Username
+	}
+	if other.Host != "" {
+		ui.Host = other.Host
+	}
+	if other.Realname != "" {
+		ui.Realname = other.Realname
+	}
+	if other.Account != "" {
+		ui.Account = other.Account
+	}
+}
+
+type networkUser struct {
+	info     UserInfo
+	channels map[string]*NetworkChannel
+}
+
+type channelMember struct {
+	user     *networkUser
+	prefixes string
+}
+
+func (cm *channelMember) export() ChannelMember {
+	return ChannelMember{
+		Nickname: cm.user.info.Nickname,
+		User:     cm.user.info.Username,
+		Host:     cm.user.info.Host,
+		Prefixes: cm.prefixes,
+		Away:     cm.user.info.Away,
+	}
+}
+
+func (n *Network) updateUser(nickname string, update func(info *UserInfo)) bool {
+	nickname = n.Fold(nickname)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	user, ok := n.users[nickname]
+	if !ok {
+		return false
+	}
+	update(&user.info)
+
+	return true
+}
+
+func (n *Network) GetUser(nickname string) (UserInfo, bool) {
+	nickname = n.Fold(nickname)
+
+	n.cmx.Lock()
+	defer n.cmx.Unlock()
+
+	user, ok := n.users[nickname]
+	if !ok {
+		return UserInfo{}, false
+	}
+
+	return user.info, true
+}
+
+func (n *Network) addPendingWho(mask string) {
+	n.wmx.Lock()
+	defer n.wmx.Unlock()
+
+	n.pendingWho[n.Fold(mask)] = struct{}{}
+}
+
+func (n *Network) isPendingWho(mask string, finish bool) bool {
+	mask = n.Fold(mask)
+
+	n.wmx.Lock()
+	defer n.wmx.Unlock()
+
+	_, ok := n.pendingWho[mask]
+	if ok && finish {
+		delete(n.pendingWho, mask)
+	}
+
+	return ok
+}
+
+func (n *Network) requestChannelUsers(tag string) error {
+	n.addPendingWho(tag)
+
+	whoMsg := whoMessage{
+		mask: tag,
+	}
+	if n.GetISupport().WHOX {
+		whoMsg.fields = whoxFields
+		whoMsg.token = whoxToken
+	}
+	return n.conn.write(whoMsg.encode())
+}
+
+func (n *Network) Who(mask string) error {
+	whoMsg := whoMessage{
+		mask: mask,
+	}
+	return n.conn.write(whoMsg.encode())
+}
+
+func parseWhoFlags(flags string) bool {
+	return strings.HasPrefix(flags, "G")
+}
+
+func (n *Network) handleWhoReply(msg replyMessage, msgTime time.Time) bool {
+	var (
+		mask, flags string
+		info        UserInfo
+	)
+
+	switch msg.code {
+	case rpl_WHOREPLY:
+		mask = msg.getParam(0)
+		info = UserInfo{
+			Nickname: msg.getParam(4),
+			Username: msg.getParam(1),
+			Host:     msg.getParam(2),
+		}
+		flags = msg.getParam(5)
+		if _, realname, ok := strings.Cut(msg.getParam(6), " "); ok {
+			info.Realname = realname
+		}
+	case rpl_WHOSPCRPL:
+		if msg.getParam(0) != whoxToken {
+			return false
+		}
+		mask = msg.getParam(1)
+		info = UserInfo{
+			Nickname: msg.getParam(4),
+			Username: msg.getParam(2),
+			Host:     msg.getParam(3),
+			Realname: msg.getParam(7),
+		}
+		flags = msg.getParam(5)
+		if account := msg.getParam(6); account != "0" {
+			info.Account = account
+		}
+	case rpl_ENDOFWHO:
+		return n.isPendingWho(msg.getParam(0), true)
+	case rpl_WHOISUSER:
+		n.updateUser(msg.getParam(0), func(current *UserInfo) {
+			current.Username = msg.getParam(1)
+			current.Host = msg.getParam(2)
+			current.Realname = msg.getParam(4)
+		})
+		return false
+	case rpl_AWAY:
+		n.updateUser(msg.getParam(0), func(current *UserInfo) {
+			current.Away = true
+			current.AwayMessage = msg.getParam(1)
+		})
+		return false
+	default:
+		return false
+	}
+
+	away := parseWhoFlags(flags)
+	n.updateUser(info.Nickname, func(current *UserInfo) {
+		current.merge(info)
+		if current.Away != away {
+			current.AwayMessage = ""
+		}
+		current.Away = away
+	})
+
+	if n.isPendingWho(mask, false) {
+		return true
+	}
+
+	content := info.GetHostmask()
+	if info.Realname != "" {
+		content += " (" + info.Realname + ")"
+	}
+	if info.Account != "" {
+		content += " logged in as " + info.Account
+	}
+	if away {
+		content += " is away"
+	}
+	n.msgs <- NetworkMessage{
+		Time:    msgTime,
+		Content: content,
+	}
+
+	return true
+}
+
+func (n *Network) handleAway(nickname, content string) {
+	n.updateUser(nickname, func(info *UserInfo) {
+		info.Away = content != ""
+		info.AwayMessage = content
+	})
+}
+
+func (n *Network) handleChghost(nickname, user, host string) {
+	n.updateUser(nickname, func(info *UserInfo) {
+		info.Username = user
+		info.Host = host
+	})
+}
+
+func (n *Network) handleAccount(nickname, account string) {
+	n.updateUser(nickname, func(info *UserInfo) {
+		info.Account = account
+	})
+}
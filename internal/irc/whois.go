@@ -0,0 +1,158 @@
+package irc
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type WhoisResult struct {
+	Nickname   string
+	WhoWas     bool
+	Found      bool
+	User       string
+	Host       string
+	Realname   string
+	Server     string
+	ServerInfo string
+	Operator   string
+	Account    string
+	Channels   []string
+	Idle       time.Duration
+	SignOn     time.Time
+	Secure     bool
+	Away       string
+	Other      []string
+}
+
+func (n *Network) updateWhois(nickname string, add bool, update func(result *WhoisResult)) bool {
+	key := n.Fold(nickname)
+
+	n.wmx.Lock()
+	defer n.wmx.Unlock()
+
+	result, ok := n.whois[key]
+	if !ok {
+		if !add {
+			return false
+		}
+		result = &WhoisResult{
+			Nickname: nickname,
+		}
+		n.whois[key] = result
+	}
+	update(result)
+
+	return true
+}
+
+func (n *Network) finishWhois(nickname string) (WhoisResult, bool) {
+	key := n.Fold(nickname)
+
+	n.wmx.Lock()
+	defer n.wmx.Unlock()
+
+	result, ok := n.whois[key]
+	if !ok {
+		return WhoisResult{}, false
+	}
+	delete(n.whois, key)
+
+	return *result, true
+}
+
+func isWhoisReply(code uint16) bool {
+	switch code {
+	case rpl_AWAY, rpl_WHOISUSER, rpl_WHOISSERVER, rpl_WHOISOPERATOR, rpl_WHOWASUSER, rpl_WHOISCHANOP,
+		rpl_WHOISIDLE, rpl_ENDOFWHOIS, rpl_WHOISCHANNELS, rpl_WHOISACCOUNT, rpl_WHOISACTUALLY,
+		rpl_ENDOFWHOWAS, rpl_WHOISHOST, rpl_WHOISSECURE, err_NOSUCHNICK, err_WASNOSUCHNICK:
+		return true
+	}
+
+	return false
+}
+
+func (n *Network) handleWhoisReply(msg replyMessage, msgTime time.Time) bool {
+	if !isWhoisReply(msg.code) {
+		return false
+	}
+
+	nickname := msg.getParam(0)
+
+	switch msg.code {
+	case rpl_WHOISUSER, rpl_WHOWASUSER:
+		return n.updateWhois(nickname, true, func(result *WhoisResult) {
+			result.Nickname = nickname
+			result.WhoWas = msg.code == rpl_WHOWASUSER
+			result.Found = true
+			result.User = msg.getParam(1)
+			result.Host = msg.getParam(2)
+			result.Realname = msg.getParam(4)
+		})
+	case rpl_ENDOFWHOIS, rpl_ENDOFWHOWAS:
+		result, ok := n.finishWhois(nickname)
+		if !ok {
+			return true
+		}
+		result.WhoWas = msg.code == rpl_ENDOFWHOWAS
+		n.emitEvent(WhoisEvent{
+			Time:   msgTime,
+			Result: result,
+		})
+		return true
+	}
+
+	return n.updateWhois(nickname, false, func(result *WhoisResult) {
+		switch msg.code {
+		case rpl_WHOISSERVER:
+			result.Server = msg.getParam(1)
+			result.ServerInfo = msg.getParam(2)
+		case rpl_WHOISOPERATOR:
+			result.Operator = msg.getParam(1)
+		case rpl_WHOISIDLE:
+			if idle, err := strconv.ParseInt(msg.getParam(1), 10, 64); err == nil {
+				result.Idle = time.Duration(idle) * time.Second
+			}
+			if signOn, err := strconv.ParseInt(msg.getParam(2), 10, 64); err == nil {
+				result.SignOn = time.Unix(signOn, 0)
+			}
+		case rpl_WHOISCHANNELS:
+			result.Channels = append(result.Channels, strings.Fields(msg.getParam(1))...)
+		case rpl_WHOISACCOUNT:
+			result.Account = msg.getParam(1)
+		case rpl_WHOISSECURE:
+			result.Secure = true
+		case rpl_AWAY:
+			result.Away = msg.getParam(1)
+		case err_NOSUCHNICK, err_WASNOSUCHNICK:
+			result.Found = false
+		default:
+			if len(msg.params) > 1 {
+				result.Other = append(result.Other, strings.Join(msg.params[1:], " "))
+			}
+		}
+	})
+}
+
+func (n *Network) Whois(nickname string) error {
+	n.updateWhois(nickname, true, func(result *WhoisResult) {
+		result.WhoWas = false
+	})
+
+	whoisMsg := whoisMessage{
+		nickname: nickname,
+	}
+	return n.conn.write(whoisMsg.encode())
+}
+
+func (n *Network) WhoWas(nickname string) error {
+	n.updateWhois(nickname, true, func(result *WhoisResult) {
+		result.WhoWas = true
+	})
+
+	whoisMsg := whoisMessage{
+		nickname: nickname,
+		whowas:   true,
+	}
+	return n.conn.write(whoisMsg.encode())
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/franciscosbf/irc-client/internal/cmds"
+)
+
+var awayNickStyle = lipgloss.NewStyle().
+	Faint(true)
+
+func (m *model) refreshStatus() {
+	if m.network == nil {
+		return
+	}
+
+	text := connectedSlidingText(m.network.GetHost(), m.network.GetISupport())
+	if m.network.IsAway() {
+		text += " [away]"
+	}
+	if lag, ok := m.network.GetLag(); ok {
+		text += " [lag " + formatLag(lag) + "]"
+	}
+	if queued := m.network.GetQueuedCount(); queued > 0 {
+		text += fmt.Sprintf(" [queued %d]", queued)
+	}
+
+	m.sliding.SetText(text)
+}
+
+func (m *model) awayMessage(message string) string {
+	if message != "" {
+		return message
+	}
+
+	return m.config.GetNetwork(m.network.GetHost()).Away.Message
+}
+
+func (m *model) onAwayCmd(cmd cmds.AwayCmd) {
+	m.autoAway = false
+
+	if err := m.network.SetAway(m.awayMessage(cmd.Message)); err != nil {
+		m.addAppMsg("Failed to mark yourself as away")
+	}
+}
+
+func (m *model) onBackCmd() {
+	m.autoAway = false
+
+	if err := m.network.Back(); err != nil {
+		m.addAppMsg("Failed to mark yourself as back")
+	}
+}
+
+func (m *model) onPromptActivity() {
+	m.lastInput = time.Now()
+
+	if !m.autoAway || m.network == nil {
+		return
+	}
+	m.autoAway = false
+
+	if m.network.IsAway() {
+		m.onBackCmd()
+	}
+}
+
+func (m *model) checkAutoAway() {
+	if m.network == nil || !m.network.IsRegistered() || m.network.IsAway() || m.autoAway {
+		return
+	}
+
+	autoAfter := time.Duration(m.config.GetNetwork(m.network.GetHost()).Away.AutoAfter)
+	if autoAfter <= 0 || time.Since(m.lastInput) < autoAfter {
+		return
+	}
+
+	if err := m.network.SetAway(m.awayMessage("")); err != nil {
+		m.addAppMsg("Failed to mark yourself as away")
+		return
+	}
+	m.autoAway = true
+}
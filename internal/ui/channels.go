@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/franciscosbf/irc-client/internal/cmds"
+	"github.com/franciscosbf/irc-client/internal/irc"
+	"github.com/franciscosbf/irc-client/internal/ui/components/channelslist"
+)
+
+func (m *model) closeChannelsList() {
+	m.browsingChannels = false
+	m.channelsList.SetChannels(nil, "")
+}
+
+func (m *model) channelsListHeader() string {
+	sorting := "users"
+	if m.channelsList.IsSortedByName() {
+		sorting = "name"
+	}
+
+	return fmt.Sprintf("Channels (%d, sorted by %s): / filters, s sorts, enter joins, esc closes",
+		m.channelsList.GetChannelsCount(), sorting)
+}
+
+func (m *model) onListCmd(cmd cmds.ListCmd) {
+	if err := m.network.List(cmd.Conditions); err != nil {
+		m.addAppMsg("Failed to request the list of channels")
+		return
+	}
+
+	m.listFilter = cmd.Filter
+	m.addAppMsg("Requested the list of channels")
+}
+
+func (m *model) onChannelList(event irc.ChannelListEvent) {
+	channels := make([]channelslist.Channel, len(event.Channels))
+	for i, listed := range event.Channels {
+		channels[i] = channelslist.Channel{
+			Tag:   listed.Tag,
+			Users: listed.Users,
+			Topic: listed.Topic,
+		}
+	}
+
+	m.channelsList.SetChannels(channels, m.listFilter)
+	m.listFilter = ""
+	m.browsingChannels = true
+}
+
+func (m *model) onChannelsListKey(msg tea.KeyMsg) tea.Cmd {
+	if m.channelsList.IsFiltering() {
+		var cmd tea.Cmd
+		m.channelsList, cmd = m.channelsList.Update(msg)
+		return cmd
+	}
+
+	switch msg.String() {
+	case "enter":
+		tag, ok := m.channelsList.GetSelectedChannel()
+		if !ok {
+			return nil
+		}
+		m.closeChannelsList()
+		return m.onJoinCmd(cmds.JoinCmd{Tag: tag})
+	case "esc":
+		if m.channelsList.IsFiltered() {
+			m.channelsList.ResetFilter()
+		} else {
+			m.closeChannelsList()
+		}
+		return nil
+	case "s":
+		return m.channelsList.ToggleSort()
+	}
+
+	var cmd tea.Cmd
+	m.channelsList, cmd = m.channelsList.Update(msg)
+	return cmd
+}
@@ -0,0 +1,182 @@
+package channelslist
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type Channel struct {
+	Tag   string
+	Users int
+	Topic string
+}
+
+type channel struct {
+	Channel
+}
+
+func (c channel) Title() string {
+	return c.Tag
+}
+
+func (c channel) Description() string {
+	users := strconv.Itoa(c.Users) + " users"
+	if c.Topic == "" {
+		return users
+	}
+
+	return users + ": " + c.Topic
+}
+
+func (c channel) FilterValue() string {
+	return c.Tag + " " + c.Topic
+}
+
+func substringFilter(term string, targets []string) []list.Rank {
+	term = strings.ToLower(term)
+
+	ranks := []list.Rank{}
+	for i, target := range targets {
+		target = strings.ToLower(target)
+		start := strings.Index(target, term)
+		if start < 0 {
+			continue
+		}
+		start = utf8.RuneCountInString(target[:start])
+		matched := []int{}
+		for j := range utf8.RuneCountInString(term) {
+			matched = append(matched, start+j)
+		}
+		ranks = append(ranks, list.Rank{
+			Index:          i,
+			MatchedIndexes: matched,
+		})
+	}
+
+	return ranks
+}
+
+type Model struct {
+	list     list.Model
+	channels []Channel
+	byName   bool
+}
+
+func (m *Model) sortChannels() tea.Cmd {
+	slices.SortStableFunc(m.channels, func(a, b Channel) int {
+		if !m.byName {
+			if order := cmp.Compare(b.Users, a.Users); order != 0 {
+				return order
+			}
+		}
+		return strings.Compare(strings.ToLower(a.Tag), strings.ToLower(b.Tag))
+	})
+
+	items := make([]list.Item, len(m.channels))
+	for i, c := range m.channels {
+		items[i] = channel{
+			Channel: c,
+		}
+	}
+	return m.list.SetItems(items)
+}
+
+func (m *Model) SetChannels(channels []Channel, filter string) {
+	m.channels = slices.Clone(channels)
+	m.list.ResetFilter()
+	m.list.ResetSelected()
+	m.sortChannels()
+
+	if filter != "" {
+		m.list.SetFilterText(filter)
+	}
+}
+
+func (m *Model) ToggleSort() tea.Cmd {
+	m.byName = !m.byName
+	return m.sortChannels()
+}
+
+func (m Model) IsSortedByName() bool {
+	return m.byName
+}
+
+func (m Model) GetChannelsCount() int {
+	return len(m.channels)
+}
+
+func (m Model) GetSelectedChannel() (string, bool) {
+	selected, ok := m.list.SelectedItem().(channel)
+	if !ok {
+		return "", false
+	}
+
+	return selected.Tag, true
+}
+
+func (m Model) IsFiltering() bool {
+	return m.list.SettingFilter()
+}
+
+func (m Model) IsFiltered() bool {
+	return m.list.FilterState() == list.FilterApplied
+}
+
+func (m *Model) ResetFilter() {
+	m.list.ResetFilter()
+}
+
+func (m *Model) SetSize(width int, height int) {
+	m.list.SetSize(width, height)
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+
+	return m, cmd
+}
+
+func (m Model) View() string {
+	return m.list.View()
+}
+
+func InitialModel() Model {
+	m := Model{}
+
+	listDelegate := list.NewDefaultDelegate()
+	listDelegate.SetSpacing(0)
+	listDelegate.Styles.NormalTitle = lipgloss.NewStyle().
+		Bold(true).
+		PaddingLeft(2).
+		Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
+	listDelegate.Styles.NormalDesc = listDelegate.Styles.NormalDesc.
+		PaddingLeft(2)
+	listDelegate.Styles.SelectedTitle = lipgloss.NewStyle().
+		Bold(true).
+		PaddingLeft(1).
+		Border(lipgloss.Border{Left: "|"}, false, false, false, true).
+		Foreground(lipgloss.AdaptiveColor{Light: "#2fabb5", Dark: "#bde1e4"})
+	listDelegate.Styles.SelectedDesc = listDelegate.Styles.SelectedDesc.
+		PaddingLeft(1).
+		Border(lipgloss.Border{Left: "|"}, false, false, false, true).
+		BorderForeground(lipgloss.AdaptiveColor{Light: "#2fabb5", Dark: "#bde1e4"})
+
+	m.list = list.New([]list.Item{}, listDelegate, 0, 0)
+	m.list.Filter = substringFilter
+	m.list.SetShowTitle(false)
+	m.list.SetShowStatusBar(false)
+	m.list.SetShowHelp(false)
+	m.list.KeyMap.Quit.SetEnabled(false)
+	m.list.KeyMap.ForceQuit.SetEnabled(false)
+	m.list.Styles.PaginationStyle = lipgloss.Style{}
+
+	return m
+}
@@ -26,6 +26,10 @@ func (m Model) GetTag() string {
 	return m.tag
 }
 
+func (m *Model) SetTag(tag string) {
+	m.tag = tag
+}
+
 func (m Model) GetWidth() int {
 	return m.viewport.Width
 }
@@ -40,6 +44,12 @@ func (m *Model) AddMsg(msg string) {
 	m.setContent()
 }
 
+func (m *Model) SetMsgs(msgs []string) {
+	m.msgs = msgs
+
+	m.setContent()
+}
+
 func (m *Model) ScrollOneLineUp() {
 	m.viewport.ScrollUp(1)
 }
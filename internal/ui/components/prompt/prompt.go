@@ -11,7 +11,7 @@ import (
 const maxPromptInput = 300
 
 var suppressedKeys = key.NewBinding(key.WithKeys(
-	"alt+h", "alt+j", "alt+k", "alt+l", "alt+b", "alt+n", "alt+p", "alt+t",
+	"alt+h", "alt+j", "alt+k", "alt+l", "alt+b", "alt+n", "alt+p", "alt+t", "alt+y", "alt+x",
 ))
 
 func trimRight(input string) string {
@@ -23,7 +23,6 @@ func Blink() tea.Cmd {
 }
 
 type Model struct {
-	lastInput  string
 	history    []string
 	historyPos int
 	input      textinput.Model
@@ -40,15 +39,14 @@ func (m Model) GetPromptWidth() int {
 func (m *Model) GetInputAndResetIt() string {
 	input := m.input.Value()
 	input = trimRight(input)
-	m.lastInput = input
 
 	m.input.Reset()
 
 	return input
 }
 
-func (m *Model) AddLastInputToHistory() {
-	m.history = append(m.history, m.lastInput)
+func (m *Model) AddToHistory(input string) {
+	m.history = append(m.history, input)
 	m.historyPos = len(m.history)
 }
 
@@ -37,8 +37,19 @@ func (m *Model) SetWidth(w int) {
 }
 
 func (m *Model) SetText(text string) {
+	if m.text == text {
+		return
+	}
+
+	keepPos := len(m.text) == len(text)
+	pos := m.pos
+
 	m.text = text
 	m.addjustWindowSize()
+
+	if keepPos {
+		m.pos = pos
+	}
 }
 
 func (m Model) Init() tea.Cmd {
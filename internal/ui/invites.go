@@ -0,0 +1,57 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/franciscosbf/irc-client/internal/cmds"
+	"github.com/franciscosbf/irc-client/internal/irc"
+)
+
+func (m *model) onInviteCmd(cmd cmds.InviteCmd) {
+	tag, ok := m.channelTarget(cmd.Tag)
+	if !ok {
+		return
+	}
+
+	if err := m.network.Invite(cmd.Nickname, tag); err != nil {
+		m.addAppMsg("Failed to invite " + cmd.Nickname + " to " + tag)
+	}
+}
+
+func (m *model) onKnockCmd(cmd cmds.KnockCmd) {
+	if err := m.network.Knock(cmd.Tag, cmd.Message); err != nil {
+		m.addAppMsg("Failed to knock on " + cmd.Tag)
+	}
+}
+
+func (m *model) onInvite(event irc.InviteEvent) tea.Cmd {
+	m.addMsg(networkChatIndex, event.Time, event.Inviter+" invited you to "+event.Tag)
+
+	if _, ok := m.modeledChannels[m.fold(event.Tag)]; ok {
+		return nil
+	}
+
+	if event.AutoJoin {
+		return m.onJoinCmd(cmds.JoinCmd{Tag: event.Tag})
+	}
+
+	m.askQuestion(question{
+		text: event.Inviter + " invites you to " + event.Tag,
+		accept: func(m *model) tea.Cmd {
+			if _, ok := m.modeledChannels[m.fold(event.Tag)]; ok {
+				return nil
+			}
+			return m.onJoinCmd(cmds.JoinCmd{Tag: event.Tag})
+		},
+		reject: func(m *model) {},
+	})
+
+	return nil
+}
+
+func (m *model) onJoinFailed(event irc.JoinFailedEvent) {
+	if chatChannel, ok := m.modeledChannels[m.fold(event.Tag)]; ok {
+		m.removeChat(chatChannel.index)
+	}
+
+	m.addMsg(networkChatIndex, event.Time, "Couldn't join "+event.Tag+": "+event.Reason)
+}
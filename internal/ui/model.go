@@ -3,12 +3,15 @@ package ui
 import (
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/franciscosbf/irc-client/internal/cmds"
+	"github.com/franciscosbf/irc-client/internal/config"
 	"github.com/franciscosbf/irc-client/internal/irc"
+	"github.com/franciscosbf/irc-client/internal/ui/components/channelslist"
 	"github.com/franciscosbf/irc-client/internal/ui/components/chat"
 	"github.com/franciscosbf/irc-client/internal/ui/components/chatslist"
 	"github.com/franciscosbf/irc-client/internal/ui/components/prompt"
@@ -20,6 +23,7 @@ const (
 	slidingInterval  = 250 * time.Millisecond
 	networkChatIndex = 0
 	timeFormat       = "15:04"
+	dateTimeFormat   = "Jan 2 15:04"
 )
 
 var notConnectedSlidingText = "Not connected"
@@ -43,12 +47,31 @@ var nickNameStyle = lipgloss.NewStyle().
 	Bold(true).
 	Foreground(lipgloss.AdaptiveColor{Light: "#1a1a1a", Dark: "#dddddd"})
 
+var actionStyle = lipgloss.NewStyle().
+	Italic(true).
+	Foreground(lipgloss.AdaptiveColor{Light: "#8a3fa0", Dark: "#d7a8e6"})
+
+var headerStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.AdaptiveColor{Light: "#2fabb5", Dark: "#bde1e4"}).
+	PaddingLeft(1)
+
 var timeStyle = lipgloss.NewStyle().
 	Bold(true).
 	Foreground(lipgloss.AdaptiveColor{Light: "#3c3c3c", Dark: "#a8a8a8"})
 
-func currentTime() string {
-	return time.Now().Format(timeFormat)
+func formatTime(t time.Time) string {
+	now := time.Now()
+	if t.IsZero() {
+		t = now
+	}
+	t = t.Local()
+
+	if y, m, d := t.Date(); y != now.Year() || m != now.Month() || d != now.Day() {
+		return t.Format(dateTimeFormat)
+	}
+
+	return t.Format(timeFormat)
 }
 
 type networkMsg struct {
@@ -57,9 +80,22 @@ type networkMsg struct {
 	isOpen  bool
 }
 
+type eventMsg struct {
+	network *irc.Network
+	event   irc.Event
+	isOpen  bool
+}
+
+type conversation interface {
+	GetTag() string
+	SendMessage(content string) error
+	SendAction(content string) error
+	ReceiveMessage() (irc.ChannelMessage, bool)
+}
+
 type channelMsg struct {
 	network *irc.Network
-	channel *irc.NetworkChannel
+	channel conversation
 	msg     irc.ChannelMessage
 	isOpen  bool
 }
@@ -81,7 +117,18 @@ func networkMsgCmd(network *irc.Network) tea.Cmd {
 	}
 }
 
-func channelMsgCmd(network *irc.Network, channel *irc.NetworkChannel) tea.Cmd {
+func eventMsgCmd(network *irc.Network) tea.Cmd {
+	return func() tea.Msg {
+		event, ok := network.ReceiveEvent()
+		return eventMsg{
+			network: network,
+			event:   event,
+			isOpen:  ok,
+		}
+	}
+}
+
+func channelMsgCmd(network *irc.Network, channel conversation) tea.Cmd {
 	return func() tea.Msg {
 		msg, ok := channel.ReceiveMessage()
 		return channelMsg{
@@ -93,9 +140,9 @@ func channelMsgCmd(network *irc.Network, channel *irc.NetworkChannel) tea.Cmd {
 	}
 }
 
-func connectionMsgCmd(cmd cmds.ConnectCmd) tea.Cmd {
+func connectionMsgCmd(cmd cmds.ConnectCmd, networkConfig config.Network) tea.Cmd {
 	return func() tea.Msg {
-		conn, err := irc.DialNetworkConnection(cmd.Host)
+		conn, err := irc.DialNetworkConnection(cmd.Host, networkConfig)
 		return connectionMsg{
 			cmd:  cmd,
 			conn: conn,
@@ -122,19 +169,29 @@ func (cd *connDialup) unregister() {
 
 type modeledChannel struct {
 	index   int
-	channel *irc.NetworkChannel
+	channel conversation
 }
 
 type model struct {
-	connDialup      connDialup
-	network         *irc.Network
-	modeledChannels map[string]modeledChannel
-	chatsList       chatslist.Model
-	chats           []chat.Model
-	prevActiveChat  int
-	activeChatIndex int
-	prompt          prompt.Model
-	sliding         textsliding.Model
+	config           config.Config
+	connDialup       connDialup
+	identity         cmds.ConnectCmd
+	reconnecting     *reconnection
+	network          *irc.Network
+	modeledChannels  map[string]modeledChannel
+	chatsList        chatslist.Model
+	chats            []chat.Model
+	prevActiveChat   int
+	activeChatIndex  int
+	prompt           prompt.Model
+	sliding          textsliding.Model
+	questions        []question
+	channelsList     channelslist.Model
+	browsingChannels bool
+	listFilter       string
+	lastInput        time.Time
+	autoAway         bool
+	width, height    int
 }
 
 func (m *model) setActiveChat(index int) {
@@ -163,6 +220,8 @@ func (m *model) disconnectFromNetwork() bool {
 }
 
 func (m *model) addaptToWindowSize(width, height int) {
+	m.width, m.height = width, height
+
 	leftSlice := int(float64(width) * 0.12)
 	rightSlice := width - leftSlice
 	mod := func(x int) int {
@@ -174,7 +233,14 @@ func (m *model) addaptToWindowSize(width, height int) {
 	}
 	m.chatsList.SetSize(mod(leftSlice-2), mod(height-3))
 	m.sliding.SetWidth(mod(leftSlice - 3))
-	m.chats[m.activeChatIndex].SetSize(mod(rightSlice-2), mod(height-3))
+	chatHeight := height - 4
+	if len(m.questions) > 0 {
+		chatHeight--
+	}
+	for i := range m.chats {
+		m.chats[i].SetSize(mod(rightSlice-2), mod(chatHeight))
+	}
+	m.channelsList.SetSize(mod(rightSlice-2), mod(chatHeight))
 	m.prompt.SetWidth(rightSlice)
 
 	if m.chats[m.activeChatIndex].PastBottom() {
@@ -182,6 +248,10 @@ func (m *model) addaptToWindowSize(width, height int) {
 	}
 }
 
+func (m *model) relayout() {
+	m.addaptToWindowSize(m.width, m.height)
+}
+
 func (m *model) goToPreviousChat() {
 	m.setActiveChat(max(0, m.activeChatIndex-1))
 	m.chatsList.SetSelectedChat(m.activeChatIndex)
@@ -192,10 +262,83 @@ func (m *model) goToNextChat() {
 	m.chatsList.SetSelectedChat(m.activeChatIndex)
 }
 
+func (m *model) isupport() irc.ISupport {
+	if m.network == nil {
+		return irc.DefaultISupport()
+	}
+
+	return m.network.GetISupport()
+}
+
+func (m *model) fold(tag string) string {
+	return m.isupport().Fold(tag)
+}
+
+func (m *model) refoldChats() {
+	modeledChannels := map[string]modeledChannel{}
+	for _, chatChannel := range m.modeledChannels {
+		modeledChannels[m.fold(m.chats[chatChannel.index].GetTag())] = chatChannel
+	}
+	m.modeledChannels = modeledChannels
+}
+
+func connectedSlidingText(host string, isupport irc.ISupport) string {
+	if isupport.Network == "" {
+		return "Connected to network " + host
+	}
+
+	return fmt.Sprintf("Connected to network %s (%s)", isupport.Network, host)
+}
+
+func formatLag(lag time.Duration) string {
+	return fmt.Sprintf("%.2fs", lag.Seconds())
+}
+
+func (m *model) onSendFailed(event irc.SendFailedEvent) {
+	msg := "Failed to send to the network: " + event.Err.Error()
+	if event.Dropped > 0 {
+		msg += fmt.Sprintf(" (%d queued messages dropped)", event.Dropped)
+	}
+
+	m.addAppMsg(msg)
+}
+
+func (m *model) networkConfig(cmd cmds.ConnectCmd) config.Network {
+	networkConfig := m.config.GetNetwork(cmd.Host)
+	if cmd.SASLAccount != "" {
+		networkConfig.SASL = config.SASL{
+			Account:  cmd.SASLAccount,
+			Password: cmd.SASLPassword,
+		}
+	}
+
+	return networkConfig
+}
+
 func (m *model) onHelpCmd(cmd cmds.HelpCmd) {
 	m.addAppMsg(cmd.HelpMsg())
 }
 
+func (m *model) onCertFPCmd(cmd cmds.CertFPCmd) {
+	host := cmd.Host
+	if host == "" {
+		if m.network == nil {
+			m.addAppMsg("No current network")
+			return
+		}
+		host = m.network.GetHost()
+	}
+
+	cert, err := irc.LoadClientCertificate(m.config.GetNetwork(host).ClientCert)
+	if err != nil {
+		m.addAppMsg(fmt.Sprintf("Failed to get client certificate of %s: %v", host, err))
+		return
+	}
+
+	m.addAppMsg(fmt.Sprintf("Client certificate fingerprint (SHA-256) of %s: %s",
+		host, irc.CertificateFingerprint(cert)))
+}
+
 func (m *model) onQuitCmd() {
 	m.quitCurrentNetwork()
 }
@@ -206,9 +349,13 @@ func (m *model) onConnectCmd(cmd cmds.ConnectCmd) tea.Cmd {
 		return nil
 	}
 
+	if m.reconnecting != nil {
+		m.cancelReconnect()
+	}
+
 	m.connDialup.register(cmd.Host)
 
-	return connectionMsgCmd(cmd)
+	return connectionMsgCmd(cmd, m.networkConfig(cmd))
 }
 
 func (m *model) onDisconnectCmd() {
@@ -223,27 +370,65 @@ func (m *model) onNickCmd(cmd cmds.NickCmd) {
 	}
 }
 
-func (m *model) onJoinCmd(cmd cmds.JoinCmd) tea.Cmd {
-	if _, ok := m.modeledChannels[cmd.Tag]; ok {
-		m.addAppMsg("Already in channel " + cmd.Tag)
-	} else if channel, err := m.network.JoinChannel(cmd.Tag); err == nil {
-		prevActiveChat := m.chats[m.activeChatIndex]
+func (m *model) addChat(channel conversation, focus bool) tea.Cmd {
+	tag := channel.GetTag()
+	activeChat := m.chats[m.activeChatIndex]
 
-		m.setActiveChat(len(m.chats))
+	m.chats = append(m.chats, chat.InitialModel(tag))
+	index := len(m.chats) - 1
 
-		m.chats = append(m.chats, chat.InitialModel(cmd.Tag))
+	m.modeledChannels[m.fold(tag)] = modeledChannel{
+		index:   index,
+		channel: channel,
+	}
 
-		m.modeledChannels[cmd.Tag] = modeledChannel{
-			index:   m.activeChatIndex,
-			channel: channel,
+	m.chats[index].SetSize(activeChat.GetWidth(), activeChat.GetHeight())
+	m.chatsList.SetChats(m.chats)
+
+	if focus {
+		m.setActiveChat(index)
+	}
+	m.chatsList.SetSelectedChat(m.activeChatIndex)
+
+	return channelMsgCmd(m.network, channel)
+}
+
+func (m *model) removeChat(index int) {
+	delete(m.modeledChannels, m.fold(m.chats[index].GetTag()))
+
+	m.chats = append(m.chats[:index], m.chats[index+1:]...)
+	m.chatsList.SetChats(m.chats)
+
+	for i := index; i < len(m.chats); i++ {
+		if modeledChannel, ok := m.modeledChannels[m.fold(m.chats[i].GetTag())]; ok {
+			modeledChannel.index--
+			m.modeledChannels[m.fold(m.chats[i].GetTag())] = modeledChannel
 		}
+	}
 
-		m.chats[m.activeChatIndex].SetSize(prevActiveChat.GetWidth(), prevActiveChat.GetHeight())
-		m.chatsList.SetChats(m.chats)
-		m.chatsList.SetSelectedChat(m.activeChatIndex)
+	if m.activeChatIndex >= index {
+		m.setActiveChat(m.activeChatIndex - 1)
+	}
+	m.chatsList.SetSelectedChat(m.activeChatIndex)
+}
+
+func (m *model) renameChat(index int, tag string) {
+	oldTag := m.chats[index].GetTag()
+	modeledChannel := m.modeledChannels[m.fold(oldTag)]
 
-		return channelMsgCmd(m.network, channel)
+	delete(m.modeledChannels, m.fold(oldTag))
+	m.modeledChannels[m.fold(tag)] = modeledChannel
+
+	m.chats[index].SetTag(tag)
+	m.chatsList.SetChats(m.chats)
+	m.chatsList.SetSelectedChat(m.activeChatIndex)
+}
 
+func (m *model) onJoinCmd(cmd cmds.JoinCmd) tea.Cmd {
+	if _, ok := m.modeledChannels[m.fold(cmd.Tag)]; ok {
+		m.addAppMsg("Already in channel " + cmd.Tag)
+	} else if channel, err := m.network.JoinChannel(cmd.Tag, cmd.Key); err == nil {
+		return m.addChat(channel, true)
 	} else {
 		m.addAppMsg("Failed to join channel " + cmd.Tag)
 	}
@@ -251,25 +436,39 @@ func (m *model) onJoinCmd(cmd cmds.JoinCmd) tea.Cmd {
 	return nil
 }
 
-func (m *model) onPartCmd(cmd cmds.PartCmd) {
-	if chatChannel, ok := m.modeledChannels[cmd.Tag]; ok {
-		delete(m.modeledChannels, chatChannel.channel.GetTag())
+func (m *model) onQueryOpened(event irc.QueryOpenedEvent) tea.Cmd {
+	key := m.fold(event.Query.GetTag())
 
-		m.chats = append(m.chats[:chatChannel.index], m.chats[chatChannel.index+1:]...)
-		m.chatsList.SetChats(m.chats)
+	chatChannel, ok := m.modeledChannels[key]
+	if !ok {
+		return m.addChat(event.Query, false)
+	}
 
-		if m.activeChatIndex >= chatChannel.index {
-			for i := m.activeChatIndex; i < len(m.chats); i++ {
-				modeledChannel := m.modeledChannels[m.chats[i].GetTag()]
-				modeledChannel.index--
-				m.modeledChannels[m.chats[i].GetTag()] = modeledChannel
-			}
-			m.setActiveChat(m.activeChatIndex - 1)
+	if chatChannel.channel == event.Query {
+		return nil
+	}
+	if _, ok := chatChannel.channel.(*irc.NetworkQuery); !ok {
+		event.Query.Close()
+		return nil
+	}
+
+	chatChannel.channel = event.Query
+	m.modeledChannels[key] = chatChannel
+
+	return channelMsgCmd(m.network, event.Query)
+}
+
+func (m *model) onPartCmd(cmd cmds.PartCmd) {
+	if chatChannel, ok := m.modeledChannels[m.fold(cmd.Tag)]; ok {
+		channel, ok := chatChannel.channel.(*irc.NetworkChannel)
+		if !ok {
+			m.addAppMsg(cmd.Tag + " isn't a channel")
+			return
 		}
-		m.chatsList.SetSelectedChat(m.activeChatIndex)
 
-		if err := chatChannel.channel.Part(); err == nil {
-		} else {
+		m.removeChat(chatChannel.index)
+
+		if err := channel.Part(); err != nil {
 			m.addAppMsg("Failed to part channel " + cmd.Tag)
 		}
 
@@ -279,21 +478,285 @@ func (m *model) onPartCmd(cmd cmds.PartCmd) {
 	m.addAppMsg("Not in channel " + cmd.Tag)
 }
 
+func (m *model) sendMsg(chatIndex int, channel conversation, content string) {
+	if err := channel.SendMessage(content); err != nil {
+		m.addAppMsg("Failed to send message to " + channel.GetTag())
+		return
+	}
+
+	if m.isService(channel.GetTag()) {
+		content = irc.MaskServiceCommand(content)
+	}
+
+	m.addChannelMsg(chatIndex, irc.ChannelMessage{
+		Sender:  m.network.GetNickname(),
+		Content: content,
+	})
+}
+
 func (m *model) onMsgCmd(cmd cmds.MsgCmd) {
 	if m.activeChatIndex == networkChatIndex {
 		return
 	}
 
-	modeledChannel := m.modeledChannels[m.chats[m.activeChatIndex].GetTag()]
-	if err := modeledChannel.channel.SendMessage(cmd.MsgContent); err == nil {
-		m.addChannelMsg(m.activeChatIndex, irc.ChannelMessage{
-			Sender:  m.network.GetNickname(),
-			Content: cmd.MsgContent,
-		})
+	modeledChannel, ok := m.modeledChannels[m.fold(m.chats[m.activeChatIndex].GetTag())]
+	if !ok {
+		return
+	}
+	m.sendMsg(m.activeChatIndex, modeledChannel.channel, cmd.MsgContent)
+}
+
+func (m *model) onPrivMsgCmd(cmd cmds.PrivMsgCmd) tea.Cmd {
+	var teaCmds []tea.Cmd
+
+	for target := range strings.SplitSeq(cmd.Target, ",") {
+		chatChannel, ok := m.modeledChannels[m.fold(target)]
+		if !ok {
+			if m.network.IsChannel(target) {
+				m.addAppMsg("Not in channel " + target)
+				continue
+			}
+			teaCmds = append(teaCmds, m.addChat(m.network.OpenQuery(target), false))
+			chatChannel = m.modeledChannels[m.fold(target)]
+		}
+
+		m.sendMsg(chatChannel.index, chatChannel.channel, cmd.MsgContent)
+	}
+
+	return tea.Batch(teaCmds...)
+}
+
+func (m *model) onQueryCmd(cmd cmds.QueryCmd) tea.Cmd {
+	if chatChannel, ok := m.modeledChannels[m.fold(cmd.Nickname)]; ok {
+		m.setActiveChat(chatChannel.index)
+		m.chatsList.SetSelectedChat(m.activeChatIndex)
+		return nil
+	}
+
+	return m.addChat(m.network.OpenQuery(cmd.Nickname), true)
+}
+
+func (m *model) onDCCChatCmd(cmd cmds.DCCChatCmd) tea.Cmd {
+	var closed *modeledChannel
+	for key, chatChannel := range m.modeledChannels {
+		chat, ok := chatChannel.channel.(*irc.DCCChat)
+		if !ok || chat.GetPeer() != cmd.Nickname {
+			continue
+		}
+		m.setActiveChat(chatChannel.index)
+		m.chatsList.SetSelectedChat(m.activeChatIndex)
+		if !chat.IsClosed() {
+			return nil
+		}
+		delete(m.modeledChannels, key)
+		closed = &chatChannel
+		break
+	}
+
+	chat, err := m.network.OfferChat(cmd.Nickname)
+	if err != nil {
+		m.addAppMsg(fmt.Sprintf("Failed to offer a chat to %s: %v", cmd.Nickname, err))
+		return nil
+	}
+
+	if closed == nil {
+		return m.addChat(chat, true)
+	}
+	closed.channel = chat
+	m.modeledChannels[m.fold(chat.GetTag())] = *closed
+
+	return channelMsgCmd(m.network, chat)
+}
+
+func (m *model) onDCCChatOffer(chat *irc.DCCChat) {
+	m.askQuestion(question{
+		text: chat.GetPeer() + " offers a direct chat",
+		accept: func(m *model) tea.Cmd {
+			chat.Accept()
+			return m.addChat(chat, true)
+		},
+		reject: func(m *model) {
+			chat.Reject()
+		},
+	})
+}
+
+func (m *model) onMeCmd(cmd cmds.MeCmd) {
+	if m.activeChatIndex == networkChatIndex {
+		m.addAppMsg("Actions can't be sent to the network chat")
+		return
+	}
+
+	modeledChannel, ok := m.modeledChannels[m.fold(m.chats[m.activeChatIndex].GetTag())]
+	if !ok {
+		m.addAppMsg("Actions can't be sent to " + m.chats[m.activeChatIndex].GetTag())
+		return
+	}
+	if err := modeledChannel.channel.SendAction(cmd.Action); err != nil {
+		m.addAppMsg("Failed to send action to " + modeledChannel.channel.GetTag())
+		return
+	}
+
+	m.addChannelMsg(m.activeChatIndex, irc.ChannelMessage{
+		Kind:    irc.ActionMessage,
+		Sender:  m.network.GetNickname(),
+		Content: cmd.Action,
+	})
+}
+
+func (m *model) onCTCPCmd(cmd cmds.CTCPCmd) {
+	if err := m.network.SendCTCP(cmd.Target, cmd.Command, cmd.Params); err != nil {
+		m.addAppMsg("Failed to send CTCP " + cmd.Command + " to " + cmd.Target)
+		return
+	}
+
+	m.addAppMsg("Sent CTCP " + cmd.Command + " to " + cmd.Target)
+}
+
+func (m *model) onNamesCmd(cmd cmds.NamesCmd) {
+	tag := cmd.Tag
+	if tag == "" {
+		tag = m.chats[m.activeChatIndex].GetTag()
+	}
+
+	chatChannel, ok := m.modeledChannels[m.fold(tag)]
+	if !ok {
+		m.addAppMsg("Not in channel " + tag)
+		return
+	}
+	channel, ok := chatChannel.channel.(*irc.NetworkChannel)
+	if !ok {
+		m.addAppMsg(tag + " isn't a channel")
+		return
+	}
+
+	members := channel.GetMembers()
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		name := member.GetPrefix() + member.Nickname
+		if member.Away {
+			name = awayNickStyle.Render(name)
+		}
+		names = append(names, name)
+	}
+
+	m.addChannelMsg(chatChannel.index, irc.ChannelMessage{
+		Content: fmt.Sprintf("Members of %s (%d): %s", channel.GetTag(), len(members), strings.Join(names, " ")),
+	})
+}
+
+func (m *model) onModeCmd(cmd cmds.ModeCmd) {
+	if err := m.network.SetMode(cmd.Target, cmd.Modes, cmd.Args); err != nil {
+		m.addAppMsg("Failed to send modes of " + cmd.Target)
+	}
+}
+
+func (m *model) channelTarget(tag string) (string, bool) {
+	if tag != "" {
+		return tag, true
+	}
+
+	tag = m.chats[m.activeChatIndex].GetTag()
+	if !m.network.IsChannel(tag) {
+		m.addAppMsg("The current chat isn't a channel")
+		return "", false
+	}
+
+	return tag, true
+}
+
+func (m *model) onTopicCmd(cmd cmds.TopicCmd) {
+	tag, ok := m.channelTarget(cmd.Tag)
+	if !ok {
+		return
+	}
+
+	var err error
+	if cmd.Delete {
+		err = m.network.SetTopic(tag, "")
+	} else if cmd.Topic == "" {
+		err = m.network.RequestTopic(tag)
+	} else {
+		err = m.network.SetTopic(tag, cmd.Topic)
+	}
+	if err != nil {
+		m.addAppMsg("Failed to send topic of " + tag)
+	}
+}
+
+func (m *model) onKickCmd(cmd cmds.KickCmd) {
+	tag, ok := m.channelTarget(cmd.Tag)
+	if !ok {
+		return
+	}
+
+	if err := m.network.Kick(tag, cmd.Nickname, cmd.Reason); err != nil {
+		m.addAppMsg("Failed to kick " + cmd.Nickname + " from " + tag)
+	}
+}
+
+func (m *model) onKickBanCmd(cmd cmds.KickBanCmd) {
+	tag, ok := m.channelTarget(cmd.Tag)
+	if !ok {
+		return
+	}
+
+	if err := m.network.KickBan(tag, cmd.Nickname, cmd.Reason); err != nil {
+		m.addAppMsg("Failed to kickban " + cmd.Nickname + " from " + tag)
+	}
+}
+
+func (m *model) onBanCmd(tag, target string, ban bool) {
+	tag, ok := m.channelTarget(tag)
+	if !ok {
+		return
+	}
+
+	var err error
+	if ban {
+		err = m.network.Ban(tag, target)
+	} else {
+		err = m.network.Unban(tag, target)
+	}
+	if err != nil {
+		m.addAppMsg("Failed to change bans of " + tag)
+	}
+}
+
+func (m *model) onMemberModeCmd(tag string, adding bool, mode byte, nicknames []string) {
+	tag, ok := m.channelTarget(tag)
+	if !ok {
+		return
+	}
+
+	if err := m.network.SetChannelModes(tag, adding, mode, nicknames); err != nil {
+		m.addAppMsg("Failed to send modes of " + tag)
+	}
+}
+
+func (m *model) onCloseCmd() {
+	if m.activeChatIndex == networkChatIndex {
+		m.addAppMsg("The network chat can't be closed")
+		return
+	}
+
+	tag := m.chats[m.activeChatIndex].GetTag()
+	chatChannel, ok := m.modeledChannels[m.fold(tag)]
+	if !ok {
+		m.removeChat(m.activeChatIndex)
 		return
 	}
 
-	m.addAppMsg("Failed to send message to channel " + modeledChannel.channel.GetTag())
+	switch channel := chatChannel.channel.(type) {
+	case *irc.NetworkQuery:
+		m.removeChat(m.activeChatIndex)
+		channel.Close()
+	case *irc.DCCChat:
+		m.removeChat(m.activeChatIndex)
+		channel.Close()
+	default:
+		m.addAppMsg("Use /part to leave channel " + tag)
+	}
 }
 
 func (m *model) interpretUserInput() (teaCmd tea.Cmd, exit bool) {
@@ -302,7 +765,7 @@ func (m *model) interpretUserInput() (teaCmd tea.Cmd, exit bool) {
 		return
 	}
 
-	cmd, err := cmds.Parse(input)
+	cmd, err := cmds.Parse(input, m.isupport())
 	if err != nil {
 		m.addAppMsg(err.Error())
 		return
@@ -311,6 +774,8 @@ func (m *model) interpretUserInput() (teaCmd tea.Cmd, exit bool) {
 	switch cmd := cmd.(type) {
 	case cmds.HelpCmd:
 		m.onHelpCmd(cmd)
+	case cmds.CertFPCmd:
+		m.onCertFPCmd(cmd)
 	case cmds.QuitCmd:
 		m.onQuitCmd()
 		exit = true
@@ -319,6 +784,11 @@ func (m *model) interpretUserInput() (teaCmd tea.Cmd, exit bool) {
 			m.addAppMsg("Still waiting to connect to " + m.connDialup.host)
 		} else if cmd.GetType() == cmds.Connect {
 			teaCmd = m.onConnectCmd(cmd.(cmds.ConnectCmd))
+		} else if cmd.GetType() == cmds.Reconnect {
+			teaCmd = m.onReconnectCmd()
+		} else if cmd.GetType() == cmds.Disconnect && m.network == nil && m.reconnecting != nil {
+			m.addAppMsg("Stopped reconnecting to " + m.reconnecting.cmd.Host)
+			m.cancelReconnect()
 		} else if m.network == nil {
 			if cmd.GetType() != cmds.Msg {
 				m.addAppMsg("No current network")
@@ -343,13 +813,73 @@ func (m *model) interpretUserInput() (teaCmd tea.Cmd, exit bool) {
 					m.onPartCmd(cmd)
 				case cmds.MsgCmd:
 					m.onMsgCmd(cmd)
+				case cmds.PrivMsgCmd:
+					teaCmd = m.onPrivMsgCmd(cmd)
+				case cmds.QueryCmd:
+					teaCmd = m.onQueryCmd(cmd)
+				case cmds.CloseCmd:
+					m.onCloseCmd()
+				case cmds.MeCmd:
+					m.onMeCmd(cmd)
+				case cmds.CTCPCmd:
+					m.onCTCPCmd(cmd)
+				case cmds.DCCSendCmd:
+					m.onDCCSendCmd(cmd)
+				case cmds.DCCCancelCmd:
+					m.onDCCCancelCmd(cmd)
+				case cmds.DCCListCmd:
+					m.showTransfers(true)
+				case cmds.DCCChatCmd:
+					teaCmd = m.onDCCChatCmd(cmd)
+				case cmds.NamesCmd:
+					m.onNamesCmd(cmd)
+				case cmds.ModeCmd:
+					m.onModeCmd(cmd)
+				case cmds.TopicCmd:
+					m.onTopicCmd(cmd)
+				case cmds.KickCmd:
+					m.onKickCmd(cmd)
+				case cmds.KickBanCmd:
+					m.onKickBanCmd(cmd)
+				case cmds.BanCmd:
+					m.onBanCmd(cmd.Tag, cmd.Target, true)
+				case cmds.UnbanCmd:
+					m.onBanCmd(cmd.Tag, cmd.Target, false)
+				case cmds.OpCmd:
+					m.onMemberModeCmd(cmd.Tag, true, 'o', cmd.Nicknames)
+				case cmds.DeopCmd:
+					m.onMemberModeCmd(cmd.Tag, false, 'o', cmd.Nicknames)
+				case cmds.VoiceCmd:
+					m.onMemberModeCmd(cmd.Tag, true, 'v', cmd.Nicknames)
+				case cmds.DevoiceCmd:
+					m.onMemberModeCmd(cmd.Tag, false, 'v', cmd.Nicknames)
+				case cmds.WhoisCmd:
+					m.onWhoisCmd(cmd)
+				case cmds.WhoWasCmd:
+					m.onWhoWasCmd(cmd)
+				case cmds.WhoCmd:
+					m.onWhoCmd(cmd)
+				case cmds.ListCmd:
+					m.onListCmd(cmd)
+				case cmds.InviteCmd:
+					m.onInviteCmd(cmd)
+				case cmds.KnockCmd:
+					m.onKnockCmd(cmd)
+				case cmds.AwayCmd:
+					m.onAwayCmd(cmd)
+				case cmds.BackCmd:
+					m.onBackCmd()
+				case cmds.NickServCmd:
+					m.onNickServCmd(cmd)
+				case cmds.ChanServCmd:
+					m.onChanServCmd(cmd)
 				}
 			}
 		}
 	}
 
 	if cmd.GetType() != cmds.Msg {
-		m.prompt.AddLastInputToHistory()
+		m.prompt.AddToHistory(cmds.HistoryInput(input, cmd, m.isService))
 	}
 
 	return
@@ -361,28 +891,77 @@ func (m *model) interpretNetworkMsg(msg networkMsg) tea.Cmd {
 	}
 
 	if !msg.isOpen {
-		m.addAppMsg("Disconnected from the network " + msg.network.GetHost())
+		return m.onDisconnected(msg.network)
+	}
 
-		m.resetChats()
+	m.addNetworkMsg(msg.msg)
 
-		m.sliding.SetText(notConnectedSlidingText)
+	return networkMsgCmd(m.network)
+}
 
+func (m *model) interpretEventMsg(msg eventMsg) tea.Cmd {
+	if m.network != msg.network || !msg.isOpen {
 		return nil
 	}
 
-	m.addNetworkMsg(msg.msg)
+	var teaCmd tea.Cmd
+
+	switch event := msg.event.(type) {
+	case irc.RegisteredEvent:
+		teaCmd = m.onRegistered()
+	case irc.ServicesReadyEvent:
+		teaCmd = m.onServicesReady(event)
+	case irc.SendFailedEvent:
+		m.onSendFailed(event)
+	case irc.ServiceNoticeEvent:
+		m.onServiceNotice(event)
+	case irc.QueryOpenedEvent:
+		teaCmd = m.onQueryOpened(event)
+	case irc.DCCOfferEvent:
+		m.onDCCOffer(event.Transfer)
+	case irc.TransferUpdatedEvent:
+		m.onTransferUpdated(event.Transfer)
+	case irc.DCCChatOfferEvent:
+		m.onDCCChatOffer(event.Chat)
+	case irc.WhoisEvent:
+		m.onWhoisEvent(event)
+	case irc.ISupportUpdatedEvent:
+		m.refoldChats()
+		m.refreshStatus()
+	case irc.ChannelListEvent:
+		m.onChannelList(event)
+	case irc.AwayChangedEvent, irc.LagUpdatedEvent:
+		m.refreshStatus()
+	case irc.InviteEvent:
+		teaCmd = m.onInvite(event)
+	case irc.JoinFailedEvent:
+		m.onJoinFailed(event)
+	}
 
-	return networkMsgCmd(m.network)
+	return tea.Batch(teaCmd, eventMsgCmd(m.network))
+}
+
+func (m *model) findChat(channel conversation) (modeledChannel, bool) {
+	for _, chatChannel := range m.modeledChannels {
+		if chatChannel.channel != channel {
+			continue
+		}
+		if newTag := channel.GetTag(); m.chats[chatChannel.index].GetTag() != newTag {
+			m.renameChat(chatChannel.index, newTag)
+		}
+		return chatChannel, true
+	}
+
+	return modeledChannel{}, false
 }
 
 func (m *model) interpretChannelMsg(msg channelMsg) tea.Cmd {
-	if m.network != msg.network {
+	if m.network != msg.network || !msg.isOpen {
 		return nil
 	}
 
-	chatChannel, ok := m.modeledChannels[msg.channel.GetTag()]
-
-	if !ok || !msg.isOpen {
+	chatChannel, ok := m.findChat(msg.channel)
+	if !ok {
 		return nil
 	}
 
@@ -395,15 +974,25 @@ func (m *model) resetChats() {
 	m.setActiveChat(networkChatIndex)
 
 	m.chats = m.chats[:1]
+	clear(m.modeledChannels)
+
+	if m.browsingChannels {
+		m.closeChannelsList()
+	}
+
+	if len(m.questions) > 0 {
+		m.questions = nil
+		m.relayout()
+	}
 
 	m.chatsList.SetChats(m.chats)
 	m.chatsList.SetSelectedChat(m.activeChatIndex)
 }
 
-func (m *model) addMsg(chatIndex int, msg string) {
+func (m *model) addMsg(chatIndex int, msgTime time.Time, msg string) {
 	atBottom := m.chats[chatIndex].AtBottom()
 
-	time := timeStyle.Render(currentTime())
+	time := timeStyle.Render(formatTime(msgTime))
 
 	m.chats[chatIndex].AddMsg(time + " " + msg)
 
@@ -413,21 +1002,45 @@ func (m *model) addMsg(chatIndex int, msg string) {
 }
 
 func (m *model) addAppMsg(msg string) {
-	m.addMsg(networkChatIndex, appMsgStyle.Render(msg))
+	m.addMsg(networkChatIndex, time.Time{}, appMsgStyle.Render(msg))
 }
 
 func (m *model) addNetworkMsg(msg irc.NetworkMessage) {
-	m.addMsg(networkChatIndex, msg.Content)
+	m.addMsg(networkChatIndex, msg.Time, msg.Content)
+}
+
+func (m *model) senderPrefix(chatIndex int, sender string) string {
+	chatChannel, ok := m.modeledChannels[m.fold(m.chats[chatIndex].GetTag())]
+	if !ok {
+		return ""
+	}
+	channel, ok := chatChannel.channel.(*irc.NetworkChannel)
+	if !ok {
+		return ""
+	}
+	member, ok := channel.GetMember(sender)
+	if !ok {
+		return ""
+	}
+
+	return member.GetPrefix()
 }
 
 func (m *model) addChannelMsg(chatIndex int, msg irc.ChannelMessage) {
+	if msg.Kind == irc.TextMessage && msg.Sender != "" {
+		msg.Sender = m.senderPrefix(chatIndex, msg.Sender) + msg.Sender
+	}
+
 	var msgContent string
-	if msg.Sender != "" {
+	switch {
+	case msg.Kind == irc.ActionMessage:
+		msgContent = actionStyle.Render("* "+msg.Sender) + " " + msg.Content
+	case msg.Sender != "":
 		msgContent = nickNameStyle.Render(msg.Sender) + " " + msg.Content
-	} else {
+	default:
 		msgContent = msg.Content
 	}
-	m.addMsg(chatIndex, msgContent)
+	m.addMsg(chatIndex, msg.Time, msgContent)
 }
 
 func (m *model) quitCurrentNetwork() (string, bool) {
@@ -437,8 +1050,10 @@ func (m *model) quitCurrentNetwork() (string, bool) {
 
 	if err := m.network.Quit(quitMsg); err != nil {
 		log.Printf("Error when quitting network: %v\n", err)
+		m.addAppMsg("Error when quitting network: " + err.Error())
 	}
 
+	m.reconnecting = nil
 	m.resetChats()
 	host := m.network.GetHost()
 	m.network = nil
@@ -447,7 +1062,7 @@ func (m *model) quitCurrentNetwork() (string, bool) {
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(prompt.Blink(), m.sliding.Sliding())
+	return tea.Batch(prompt.Blink(), m.sliding.Sliding(), statusTickCmd())
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -466,6 +1081,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.addaptToWindowSize(msg.Width, msg.Height)
 	case tea.KeyMsg:
+		m.onPromptActivity()
+		if m.browsingChannels && msg.String() != "ctrl+c" {
+			return m, m.onChannelsListKey(msg)
+		}
 		switch msg.String() {
 		case "alt+h":
 			m.chats[m.activeChatIndex].ScrollOneColumnLeft()
@@ -483,6 +1102,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.goToNextChat()
 		case "alt+t":
 			m.toggleActiveChatWithNetworkChat()
+		case "alt+y":
+			if cmd := m.answerQuestion(true); cmd != nil {
+				appendAdditionalCmd(cmd)
+			}
+		case "alt+x":
+			m.answerQuestion(false)
 		case "enter":
 			if cmd, exit := m.interpretUserInput(); exit {
 				return m, tea.Quit
@@ -511,35 +1136,63 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case connectionMsg:
 		m.connDialup.unregister()
 		if msg.err != nil {
-			m.addAppMsg("Failed to dial connection to " + msg.cmd.Host)
+			m.addAppMsg(fmt.Sprintf("Failed to dial connection to %s: %v", msg.cmd.Host, msg.err))
+			if m.reconnecting != nil {
+				appendAdditionalCmd(m.scheduleReconnect())
+			}
 			break
 		}
 		if !msg.conn.IsSecure() {
 			m.addAppMsg(fmt.Sprintf("Connection to %s isn't secure (plain text). Caution is advised", msg.cmd.Host))
 		}
-		network := irc.NewNetwork(msg.conn)
+		network := irc.NewNetwork(msg.conn, m.networkConfig(msg.cmd))
 		network.StartListener()
 		if err := network.Register(msg.cmd.Nickname, msg.cmd.Name); err != nil {
 			m.addAppMsg("Failed to send connection registration")
+			if m.reconnecting != nil {
+				appendAdditionalCmd(m.scheduleReconnect())
+			}
 			break
 		}
 		m.network = network
-		m.sliding.SetText("Connected to network " + msg.cmd.Host)
+		m.identity = msg.cmd
+		m.lastInput = time.Now()
+		m.autoAway = false
+		m.refreshStatus()
 		appendAdditionalCmd(networkMsgCmd(network))
+		appendAdditionalCmd(eventMsgCmd(network))
 	case networkMsg:
 		if cmd := m.interpretNetworkMsg(msg); cmd != nil {
 			appendAdditionalCmd(cmd)
 		}
+	case eventMsg:
+		if cmd := m.interpretEventMsg(msg); cmd != nil {
+			appendAdditionalCmd(cmd)
+		}
 	case channelMsg:
 		if cmd := m.interpretChannelMsg(msg); cmd != nil {
 			appendAdditionalCmd(cmd)
 		}
+	case reconnectMsg:
+		if cmd := m.onReconnectMsg(msg); cmd != nil {
+			appendAdditionalCmd(cmd)
+		}
+	case statusTickMsg:
+		m.refreshTransfers()
+		m.checkAutoAway()
+		m.refreshStatus()
+		appendAdditionalCmd(statusTickCmd())
 	}
 
 	m.prompt, promptCmd = m.prompt.Update(msg)
 	m.chatsList, chatsListCmd = m.chatsList.Update(msg)
 	m.chats[m.activeChatIndex], activeChatCmd = m.chats[m.activeChatIndex].Update(msg)
 	m.sliding, slidingCmd = m.sliding.Update(msg)
+	if m.browsingChannels {
+		var channelsListCmd tea.Cmd
+		m.channelsList, channelsListCmd = m.channelsList.Update(msg)
+		appendAdditionalCmd(channelsListCmd)
+	}
 
 	cmds := tea.Batch(
 		append([]tea.Cmd{
@@ -552,27 +1205,74 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmds
 }
 
+func (m model) chatHeader(chatIndex int) string {
+	tag := m.chats[chatIndex].GetTag()
+
+	chatChannel, ok := m.modeledChannels[m.fold(tag)]
+	if !ok {
+		return tag
+	}
+	channel, ok := chatChannel.channel.(*irc.NetworkChannel)
+	if !ok {
+		return tag
+	}
+
+	topic := channel.GetTopic()
+	if topic.Text == "" {
+		return tag + " (no topic)"
+	}
+
+	header := tag + ": " + topic.Text
+	if topic.Setter != "" {
+		header += " (set by " + topic.Setter
+		if !topic.Time.IsZero() {
+			header += " at " + topic.Time.Local().Format(dateTimeFormat)
+		}
+		header += ")"
+	}
+
+	return header
+}
+
 func (m model) View() string {
 	chats := paddedBorderStyle.Render(lipgloss.PlaceHorizontal(
 		m.chatsList.GetWidth(), lipgloss.Left, m.chatsList.View()))
 	sliding := slidingStyle.Render(m.sliding.View())
 	activeChat := roundedBorderStyle.Render(m.chats[m.activeChatIndex].View())
+	headerText := m.chatHeader(m.activeChatIndex)
+	if m.browsingChannels {
+		activeChat = roundedBorderStyle.Render(lipgloss.Place(
+			m.chats[m.activeChatIndex].GetWidth(), m.chats[m.activeChatIndex].GetHeight(),
+			lipgloss.Left, lipgloss.Top, m.channelsList.View()))
+		headerText = m.channelsListHeader()
+	}
+	header := headerStyle.
+		MaxWidth(m.chats[m.activeChatIndex].GetWidth() + 2).
+		Render(headerText)
 	prompt := m.prompt.View()
 
+	conversation := []string{header, activeChat}
+	if len(m.questions) > 0 {
+		conversation = append(conversation, m.questionView())
+	}
+	conversation = append(conversation, prompt)
+
 	return lipgloss.JoinHorizontal(
 		lipgloss.Left,
 		lipgloss.JoinVertical(lipgloss.Left, chats, sliding),
-		lipgloss.JoinVertical(lipgloss.Left, activeChat, prompt))
+		lipgloss.JoinVertical(lipgloss.Left, conversation...))
 }
 
-func initialModel() model {
+func initialModel(config config.Config) model {
 	m := model{}
 
+	m.config = config
 	m.modeledChannels = map[string]modeledChannel{}
 	m.activeChatIndex = networkChatIndex
 	m.chats = []chat.Model{chat.InitialModel("network")}
 	m.chatsList = chatslist.InitialModel()
 	m.chatsList.SetChats(m.chats)
+	m.channelsList = channelslist.InitialModel()
 	m.prompt = prompt.InitialModel()
 	m.sliding = textsliding.InitialModel(notConnectedSlidingText, slidingInterval)
 
@@ -1,13 +1,16 @@
 package ui
 
-import tea "github.com/charmbracelet/bubbletea"
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/franciscosbf/irc-client/internal/config"
+)
 
-func Run() error {
+func Run(config config.Config) error {
 	options := []tea.ProgramOption{
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	}
-	program := tea.NewProgram(initialModel(), options...)
+	program := tea.NewProgram(initialModel(config), options...)
 
 	_, err := program.Run()
 	return err
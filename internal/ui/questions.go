@@ -0,0 +1,52 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var questionStyle = lipgloss.NewStyle().
+	Bold(true).
+	Foreground(lipgloss.AdaptiveColor{Light: "#b5702f", Dark: "#e4c9bd"})
+
+type question struct {
+	text   string
+	accept func(m *model) tea.Cmd
+	reject func(m *model)
+}
+
+func (m *model) askQuestion(q question) {
+	m.questions = append(m.questions, q)
+
+	if len(m.questions) == 1 {
+		m.relayout()
+	}
+}
+
+func (m *model) answerQuestion(accepted bool) tea.Cmd {
+	if len(m.questions) == 0 {
+		return nil
+	}
+
+	q := m.questions[0]
+	m.questions = m.questions[1:]
+
+	if len(m.questions) == 0 {
+		m.relayout()
+	}
+
+	if accepted {
+		return q.accept(m)
+	}
+	q.reject(m)
+
+	return nil
+}
+
+func (m model) questionView() string {
+	if len(m.questions) == 0 {
+		return ""
+	}
+
+	return questionStyle.Render(m.questions[0].text + " (alt+y accept / alt+x reject)")
+}
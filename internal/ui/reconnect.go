@@ -0,0 +1,203 @@
+package ui
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/franciscosbf/irc-client/internal/cmds"
+	"github.com/franciscosbf/irc-client/internal/config"
+	"github.com/franciscosbf/irc-client/internal/irc"
+)
+
+type reconnection struct {
+	cmd      cmds.ConnectCmd
+	policy   config.Reconnect
+	attempts int
+}
+
+type reconnectMsg struct {
+	reconnection *reconnection
+}
+
+func reconnectDelay(policy config.Reconnect, attempt int) time.Duration {
+	delay := time.Duration(policy.InitialDelay)
+	maxDelay := time.Duration(policy.MaxDelay)
+	for i := 1; i < attempt && (maxDelay <= 0 || delay < maxDelay); i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 {
+		delay = min(delay, maxDelay)
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func reconnectMsgCmd(r *reconnection, delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		return reconnectMsg{
+			reconnection: r,
+		}
+	})
+}
+
+func (m *model) markChats(marker string) {
+	for i := range m.chats {
+		m.addMsg(i, time.Time{}, appMsgStyle.Render(marker))
+	}
+}
+
+func (m *model) closeDCCChats() {
+	for _, chatChannel := range m.modeledChannels {
+		if chat, ok := chatChannel.channel.(*irc.DCCChat); ok {
+			chat.Close()
+			m.addMsg(chatChannel.index, time.Time{}, appMsgStyle.Render("Direct chat with "+chat.GetPeer()+" was closed"))
+		}
+	}
+}
+
+func (m *model) startReconnection(network *irc.Network) {
+	cmd := m.identity
+	cmd.Nickname = network.GetPrimaryNickname()
+
+	m.reconnecting = &reconnection{
+		cmd:    cmd,
+		policy: m.networkConfig(cmd).Reconnect,
+	}
+	m.network = nil
+
+	m.closeDCCChats()
+	m.markChats("Disconnected from the network " + cmd.Host)
+	m.sliding.SetText("Reconnecting to network " + cmd.Host)
+}
+
+func (m *model) scheduleReconnect() tea.Cmd {
+	r := m.reconnecting
+
+	r.attempts++
+	if r.attempts > r.policy.MaxAttempts {
+		m.addAppMsg(fmt.Sprintf("Gave up reconnecting to %s after %d attempts", r.cmd.Host, r.policy.MaxAttempts))
+		m.cancelReconnect()
+		return nil
+	}
+
+	delay := reconnectDelay(r.policy, r.attempts)
+	m.addAppMsg(fmt.Sprintf("Reconnecting to %s in %s (attempt %d of %d)",
+		r.cmd.Host, delay.Round(time.Second), r.attempts, r.policy.MaxAttempts))
+
+	return reconnectMsgCmd(r, delay)
+}
+
+func (m *model) cancelReconnect() {
+	m.reconnecting = nil
+
+	m.resetChats()
+
+	m.sliding.SetText(notConnectedSlidingText)
+}
+
+func (m *model) onDisconnected(network *irc.Network) tea.Cmd {
+	if m.reconnecting == nil {
+		if !network.IsRegistered() || !m.networkConfig(m.identity).Reconnect.Enabled {
+			m.addAppMsg("Disconnected from the network " + network.GetHost())
+			m.resetChats()
+			m.sliding.SetText(notConnectedSlidingText)
+			return nil
+		}
+		m.startReconnection(network)
+	} else {
+		m.network = nil
+	}
+
+	return m.scheduleReconnect()
+}
+
+func (m *model) onReconnectMsg(msg reconnectMsg) tea.Cmd {
+	if msg.reconnection != m.reconnecting || m.network != nil || m.connDialup.inProcess {
+		return nil
+	}
+
+	cmd := m.reconnecting.cmd
+	m.connDialup.register(cmd.Host)
+
+	return connectionMsgCmd(cmd, m.networkConfig(cmd))
+}
+
+func (m *model) onReconnectCmd() tea.Cmd {
+	switch {
+	case m.network != nil:
+		network := m.network
+		m.startReconnection(network)
+		if err := network.Quit(quitMsg); err != nil {
+			m.addAppMsg("Failed to quit the network " + network.GetHost())
+		}
+	case m.reconnecting != nil:
+		m.reconnecting = &reconnection{
+			cmd:    m.reconnecting.cmd,
+			policy: m.reconnecting.policy,
+		}
+	case m.identity.Host != "":
+		m.reconnecting = &reconnection{
+			cmd:    m.identity,
+			policy: m.networkConfig(m.identity).Reconnect,
+		}
+	default:
+		m.addAppMsg("No network to reconnect to")
+		return nil
+	}
+
+	m.reconnecting.attempts++
+	m.addAppMsg("Reconnecting to " + m.reconnecting.cmd.Host)
+
+	return reconnectMsgCmd(m.reconnecting, 0)
+}
+
+func (m *model) rejoinChats() tea.Cmd {
+	teaCmds := []tea.Cmd{}
+
+	for key, chatChannel := range m.modeledChannels {
+		var channel conversation
+		switch current := chatChannel.channel.(type) {
+		case *irc.NetworkChannel:
+			rejoined, err := m.network.JoinChannel(current.GetTag(), current.GetKey())
+			if err != nil {
+				m.addAppMsg("Failed to rejoin channel " + current.GetTag())
+				continue
+			}
+			channel = rejoined
+		case *irc.NetworkQuery:
+			channel = m.network.OpenQuery(current.GetTag())
+		default:
+			continue
+		}
+
+		chatChannel.channel = channel
+		m.modeledChannels[key] = chatChannel
+		teaCmds = append(teaCmds, channelMsgCmd(m.network, channel))
+	}
+
+	m.markChats("Reconnected to the network " + m.network.GetHost())
+
+	return tea.Batch(teaCmds...)
+}
+
+func (m *model) finishReconnection() tea.Cmd {
+	if m.reconnecting == nil {
+		return nil
+	}
+	m.reconnecting = nil
+
+	return m.rejoinChats()
+}
+
+func (m *model) onRegistered() tea.Cmd {
+	if m.networkConfig(m.identity).Services.Password != "" {
+		return nil
+	}
+
+	return m.finishReconnection()
+}
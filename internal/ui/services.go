@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/franciscosbf/irc-client/internal/cmds"
+	"github.com/franciscosbf/irc-client/internal/irc"
+)
+
+const servicesChatTag = "(services)"
+
+func (m *model) addServicesMsg(msgTime time.Time, sender string, content string) {
+	index := m.showBufferChat(servicesChatTag, false)
+
+	m.addMsg(index, msgTime, nickNameStyle.Render(sender)+" "+content)
+}
+
+func (m *model) isService(nickname string) bool {
+	return m.network != nil && m.network.IsService(nickname)
+}
+
+func (m *model) sendToService(service string, command string, send func(string) error) {
+	if err := send(command); err != nil {
+		m.addAppMsg("Failed to send command to " + service)
+		return
+	}
+
+	m.showBufferChat(servicesChatTag, true)
+	m.addServicesMsg(time.Time{}, "-> "+service, irc.MaskServiceCommand(command))
+}
+
+func (m *model) onNickServCmd(cmd cmds.NickServCmd) {
+	m.sendToService(m.network.GetNickServ(), cmd.Command, m.network.NickServ)
+}
+
+func (m *model) onChanServCmd(cmd cmds.ChanServCmd) {
+	m.sendToService(m.network.GetChanServ(), cmd.Command, m.network.ChanServ)
+}
+
+func (m *model) onServiceNotice(event irc.ServiceNoticeEvent) {
+	m.addServicesMsg(event.Time, event.Service, event.Content)
+}
+
+func (m *model) autoJoinChannels() tea.Cmd {
+	teaCmds := []tea.Cmd{}
+
+	for _, entry := range m.networkConfig(m.identity).AutoJoin {
+		tag, key, _ := strings.Cut(strings.TrimSpace(entry), " ")
+		if tag == "" {
+			continue
+		}
+		if _, ok := m.modeledChannels[m.fold(tag)]; ok {
+			continue
+		}
+
+		channel, err := m.network.JoinChannel(tag, strings.TrimSpace(key))
+		if err != nil {
+			m.addAppMsg("Failed to join channel " + tag)
+			continue
+		}
+		teaCmds = append(teaCmds, m.addChat(channel, false))
+	}
+
+	return tea.Batch(teaCmds...)
+}
+
+func (m *model) onServicesReady(event irc.ServicesReadyEvent) tea.Cmd {
+	if !event.Identified && m.networkConfig(m.identity).Services.Password != "" {
+		m.addAppMsg("Couldn't confirm the identification with " + m.network.GetNickServ())
+	}
+
+	return tea.Batch(m.finishReconnection(), m.autoJoinChannels())
+}
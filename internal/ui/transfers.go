@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/franciscosbf/irc-client/internal/cmds"
+	"github.com/franciscosbf/irc-client/internal/irc"
+	"github.com/franciscosbf/irc-client/internal/ui/components/chat"
+)
+
+const (
+	transfersChatTag   = "(transfers)"
+	progressBarWidth   = 20
+	statusTickInterval = time.Second
+)
+
+type statusTickMsg time.Time
+
+func statusTickCmd() tea.Cmd {
+	return tea.Tick(statusTickInterval, func(t time.Time) tea.Msg {
+		return statusTickMsg(t)
+	})
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func formatTransfer(transfer *irc.DCCTransfer) string {
+	size := transfer.GetSize()
+	position := transfer.GetPosition()
+
+	percentage := 100
+	if size > 0 {
+		percentage = int(min(position, size) * 100 / size)
+	}
+	filled := percentage * progressBarWidth / 100
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", progressBarWidth-filled)
+
+	var direction string
+	if transfer.IsUpload() {
+		direction = "to"
+	} else {
+		direction = "from"
+	}
+
+	line := fmt.Sprintf("#%d %s %s %s [%s] %3d%% %s/%s %s",
+		transfer.GetID(), transfer.GetFilename(), direction, transfer.GetPeer(),
+		bar, percentage, formatBytes(position), formatBytes(size), transfer.GetState())
+	if err := transfer.GetError(); err != nil {
+		line += ": " + err.Error()
+	}
+
+	return line
+}
+
+func (m *model) findChatIndex(tag string) (int, bool) {
+	for i, chat := range m.chats {
+		if chat.GetTag() == tag {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+func (m *model) showBufferChat(tag string, focus bool) int {
+	index, ok := m.findChatIndex(tag)
+	if !ok {
+		activeChat := m.chats[m.activeChatIndex]
+
+		m.chats = append(m.chats, chat.InitialModel(tag))
+		index = len(m.chats) - 1
+
+		m.chats[index].SetSize(activeChat.GetWidth(), activeChat.GetHeight())
+		m.chatsList.SetChats(m.chats)
+	}
+
+	if focus {
+		m.setActiveChat(index)
+	}
+	m.chatsList.SetSelectedChat(m.activeChatIndex)
+
+	return index
+}
+
+func (m *model) showTransfers(focus bool) {
+	m.showBufferChat(transfersChatTag, focus)
+
+	m.refreshTransfers()
+}
+
+func (m *model) refreshTransfers() {
+	if m.network == nil {
+		return
+	}
+
+	index, ok := m.findChatIndex(transfersChatTag)
+	if !ok {
+		return
+	}
+
+	lines := []string{}
+	for _, transfer := range m.network.GetTransfers() {
+		lines = append(lines, formatTransfer(transfer))
+	}
+	m.chats[index].SetMsgs(lines)
+}
+
+func (m *model) onDCCSendCmd(cmd cmds.DCCSendCmd) {
+	transfer, err := m.network.SendFile(cmd.Nickname, cmd.Path)
+	if err != nil {
+		m.addAppMsg(fmt.Sprintf("Failed to offer %s to %s: %v", cmd.Path, cmd.Nickname, err))
+		return
+	}
+
+	m.addAppMsg(fmt.Sprintf("Offered %s to %s (transfer #%d)",
+		transfer.GetFilename(), cmd.Nickname, transfer.GetID()))
+	m.showTransfers(false)
+}
+
+func (m *model) onDCCCancelCmd(cmd cmds.DCCCancelCmd) {
+	transfer, ok := m.network.GetTransfer(cmd.ID)
+	if !ok {
+		m.addAppMsg(fmt.Sprintf("No transfer #%d", cmd.ID))
+		return
+	}
+
+	transfer.Cancel()
+}
+
+func (m *model) onDCCOffer(transfer *irc.DCCTransfer) {
+	m.askQuestion(question{
+		text: fmt.Sprintf("%s offers %s (%s)",
+			transfer.GetPeer(), transfer.GetFilename(), formatBytes(transfer.GetSize())),
+		accept: func(m *model) tea.Cmd {
+			transfer.Accept()
+			m.showTransfers(false)
+			return nil
+		},
+		reject: func(m *model) {
+			transfer.Reject()
+		},
+	})
+}
+
+func (m *model) onTransferUpdated(transfer *irc.DCCTransfer) {
+	m.refreshTransfers()
+
+	switch transfer.GetState() {
+	case irc.TransferDone:
+		if transfer.IsUpload() {
+			m.addAppMsg(fmt.Sprintf("Sent %s to %s", transfer.GetFilename(), transfer.GetPeer()))
+		} else {
+			m.addAppMsg(fmt.Sprintf("Received %s from %s into %s",
+				transfer.GetFilename(), transfer.GetPeer(), transfer.GetPath()))
+		}
+	case irc.TransferFailed:
+		m.addAppMsg(fmt.Sprintf("Transfer of %s failed: %v", transfer.GetFilename(), transfer.GetError()))
+	case irc.TransferRejected:
+		if transfer.IsUpload() {
+			m.addAppMsg(fmt.Sprintf("%s rejected %s", transfer.GetPeer(), transfer.GetFilename()))
+		}
+	}
+}
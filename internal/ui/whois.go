@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/franciscosbf/irc-client/internal/cmds"
+	"github.com/franciscosbf/irc-client/internal/irc"
+)
+
+var whoisTitleStyle = lipgloss.NewStyle().
+	Bold(true).
+	Underline(true)
+
+func formatWhois(result irc.WhoisResult) string {
+	command := "WHOIS"
+	if result.WhoWas {
+		command = "WHOWAS"
+	}
+
+	lines := []string{whoisTitleStyle.Render(command + " " + result.Nickname)}
+	addLine := func(label, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("  %-9s %s", label, value))
+		}
+	}
+
+	if !result.Found {
+		if result.WhoWas {
+			addLine("status", "there was no such nickname")
+		} else {
+			addLine("status", "no such nickname")
+		}
+	} else {
+		addLine("mask", fmt.Sprintf("%s!%s@%s", result.Nickname, result.User, result.Host))
+		addLine("realname", result.Realname)
+	}
+	if result.ServerInfo != "" {
+		addLine("server", result.Server+" ("+result.ServerInfo+")")
+	} else {
+		addLine("server", result.Server)
+	}
+	addLine("account", result.Account)
+	addLine("channels", strings.Join(result.Channels, " "))
+	if result.Idle > 0 || !result.SignOn.IsZero() {
+		idle := result.Idle.String()
+		if !result.SignOn.IsZero() {
+			idle += ", signed on " + formatTime(result.SignOn)
+		}
+		addLine("idle", idle)
+	}
+	addLine("operator", result.Operator)
+	if result.Secure {
+		addLine("secure", "is using a secure connection")
+	}
+	addLine("away", result.Away)
+	for _, other := range result.Other {
+		addLine("info", other)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m *model) onWhoisCmd(cmd cmds.WhoisCmd) {
+	if err := m.network.Whois(cmd.Nickname); err != nil {
+		m.addAppMsg("Failed to request whois of " + cmd.Nickname)
+	}
+}
+
+func (m *model) onWhoWasCmd(cmd cmds.WhoWasCmd) {
+	if err := m.network.WhoWas(cmd.Nickname); err != nil {
+		m.addAppMsg("Failed to request whowas of " + cmd.Nickname)
+	}
+}
+
+func (m *model) onWhoCmd(cmd cmds.WhoCmd) {
+	if err := m.network.Who(cmd.Mask); err != nil {
+		m.addAppMsg("Failed to request who of " + cmd.Mask)
+	}
+}
+
+func (m *model) onWhoisEvent(event irc.WhoisEvent) {
+	m.addMsg(m.activeChatIndex, event.Time, formatWhois(event.Result))
+}
@@ -1,16 +1,22 @@
 package app
 
 import (
+	"github.com/franciscosbf/irc-client/internal/config"
 	"github.com/franciscosbf/irc-client/internal/logs"
 	"github.com/franciscosbf/irc-client/internal/ui"
 )
 
-func Run(logFilename string) error {
+func Run(logFilename, configFilename string) error {
 	logger, err := logs.Setup(logFilename)
 	if err != nil {
 		return err
 	}
 	defer logger.Close()
 
-	return ui.Run()
+	config, err := config.Load(configFilename)
+	if err != nil {
+		return err
+	}
+
+	return ui.Run(config)
 }